@@ -0,0 +1,375 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var alignCmd = &cobra.Command{
+	Use:     "align",
+	Short:   "Reconcile daemons with the configured workflows",
+	Long:    helpAlign,
+	Example: exampleAlign,
+
+	Args: cobra.NoArgs,
+
+	Run: runAlign,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	alignDryRun bool
+	alignGroup  string
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(alignCmd)
+
+	alignCmd.Flags().BoolVar(&alignDryRun, "dry-run", false, "Print the plan without touching any daemon")
+	alignCmd.Flags().StringVar(&alignGroup, "group", "", "Only align workflows & daemons of a specific group")
+
+	horus.CheckErr(alignCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups), horus.WithOp("align.init"), horus.WithMessage("registering config completion"))
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpAlign = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Compare the workflows in ~/.lilith/config/*.toml against invoked daemons & act on the differences:\n"+
+		"start workflows without a daemon, rekindle dead ones, restart those whose settings changed\n"+
+		"& slay daemons whose workflow was removed\n"+
+		"Daemons invoked without a workflow are left alone; one holding a workflow's name is reported as a conflict",
+)
+
+var exampleAlign = formatExample(
+	"lilith",
+	[]string{"align", "--dry-run"},
+	[]string{"align"},
+	[]string{"align", "--group", "<forge>"},
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// align actions, in the order a plan lists them
+const (
+	alignStart    = "start"
+	alignRekindle = "rekindle"
+	alignRestart  = "restart"
+	alignStop     = "stop"
+	alignConflict = "conflict"
+)
+
+// alignStep is one action of the plan; desired is the daemon to spawn, current the one to retire
+type alignStep struct {
+	Action  string
+	Name    string
+	Group   string
+	Reason  string
+	desired *DaemonMeta
+	current *DaemonMeta
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runAlign(cmd *cobra.Command, args []string) {
+	const op = "lilith.align"
+
+	plan, err := planAlign(alignGroup)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("planning alignment"))
+
+	if len(plan) == 0 {
+		fmt.Printf("%s daemons already match the workflows\n", paint(chalk.Green, "OK:"))
+		return
+	}
+
+	if alignDryRun {
+		fmt.Printf("%-9s %-20s %-15s %s\n", "ACTION", "DAEMON", "GROUP", "REASON")
+		for _, step := range plan {
+			fmt.Printf("%s %-20s %-15s %s\n", padCell(step.actionCell(), 9), step.Name, step.Group, step.Reason)
+		}
+		return
+	}
+
+	failed := 0
+	for _, step := range plan {
+		if err := step.apply(); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s %s %q: %v\n", paint(chalk.Red, "FAILED:"), step.Action, step.Name, err)
+			continue
+		}
+		fmt.Printf("%s %s %q (%s)\n", paint(chalk.Green, "OK:"), step.Action, step.Name, step.Reason)
+	}
+
+	if failed > 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(
+			op, "align_error", fmt.Sprintf("%d of %d steps failed", failed, len(plan)), nil,
+			map[string]any{"group": alignGroup},
+		))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// planAlign diffs workflows against daemon metadata. Workflows are always read in full,
+// so a daemon whose workflow moved to another group is restarted rather than slain
+func planAlign(group string) ([]alignStep, error) {
+	const op = "align.plan"
+
+	workflows, err := loadWorkflows("")
+	if err != nil {
+		return nil, err
+	}
+	// started dependencies first, as invoke --group does
+	if workflows, err = orderWorkflows(workflows); err != nil {
+		return nil, err
+	}
+	declared := map[string]workflowEntry{}
+	for _, wf := range workflows {
+		declared[wf.Name] = wf
+	}
+
+	// daemons invoked from each workflow, & ad hoc ones that may hold a workflow's name
+	spawned := map[string][]*DaemonMeta{}
+	adhoc := map[string]*DaemonMeta{}
+	var orphans []*DaemonMeta
+	for _, path := range mustListDaemonMetaFiles() {
+		meta, err := loadMeta(nameFrom(path))
+		if err != nil {
+			return nil, horus.Wrap(err, op, fmt.Sprintf("loading metadata for %q", nameFrom(path)))
+		}
+		name := workflowKey(meta, declared)
+		switch {
+		case name == "":
+			// ad hoc daemon, not ours to manage
+			adhoc[meta.Name] = meta
+		case declared[name].Name == "":
+			orphans = append(orphans, meta)
+		default:
+			spawned[name] = append(spawned[name], meta)
+		}
+	}
+
+	var plan []alignStep
+	for _, wf := range workflows {
+		if group != "" && wf.Group != group {
+			continue
+		}
+
+		daemons := spawned[wf.Name]
+		if other := adhoc[wf.Name]; len(daemons) == 0 && other != nil && isDaemonActive(other) {
+			plan = append(plan, alignStep{
+				Action: alignConflict, Name: wf.Name, Group: wf.Group,
+				Reason: fmt.Sprintf("ad hoc daemon alive with PID %d", other.PID), current: other,
+			})
+			continue
+		}
+		if len(daemons) == 0 {
+			desired, err := workflowMeta(wf)
+			if err != nil {
+				return nil, horus.Wrap(err, op, fmt.Sprintf("workflow %q", wf.Name))
+			}
+			plan = append(plan, alignStep{Action: alignStart, Name: wf.Name, Group: wf.Group, Reason: "not invoked", desired: desired})
+			continue
+		}
+
+		for _, current := range daemons {
+			logName := strings.TrimSuffix(filepath.Base(current.LogPath), ".log")
+			desired, err := newDaemonMeta(current.Name, wf.Group, logName, wf.Workflow)
+			if err != nil {
+				return nil, horus.Wrap(err, op, fmt.Sprintf("workflow %q", wf.Name))
+			}
+			desired.Workflow = wf.Name
+
+			if drift := driftOf(current, desired); drift != "" {
+				plan = append(plan, alignStep{Action: alignRestart, Name: current.Name, Group: wf.Group, Reason: drift, desired: desired, current: current})
+				continue
+			}
+			if !groupAlive(current) {
+				plan = append(plan, alignStep{Action: alignRekindle, Name: current.Name, Group: wf.Group, Reason: "dead", current: current})
+			}
+		}
+	}
+
+	for _, meta := range orphans {
+		if group != "" && meta.Group != group {
+			continue
+		}
+		plan = append(plan, alignStep{
+			Action: alignStop, Name: meta.Name, Group: meta.Group,
+			Reason: fmt.Sprintf("workflow %q removed", meta.Workflow), current: meta,
+		})
+	}
+
+	return plan, nil
+}
+
+// workflowKey names the workflow a daemon was invoked from. Metadata written before daemons
+// recorded their workflow falls back to a workflow of the same name & group
+func workflowKey(meta *DaemonMeta, declared map[string]workflowEntry) string {
+	if meta.Workflow != "" {
+		return meta.Workflow
+	}
+	if wf, ok := declared[meta.Name]; ok && wf.Group == meta.Group {
+		return meta.Name
+	}
+	return ""
+}
+
+// driftOf describes how a daemon differs from its workflow, key by key; every key is recorded
+// on the daemon when spawned, so any difference requires a restart
+func driftOf(current, desired *DaemonMeta) string {
+	var drift []string
+	if current.Group != desired.Group {
+		drift = append(drift, fmt.Sprintf("group %s → %s", current.Group, desired.Group))
+	}
+
+	have, want := reflect.ValueOf(normalWorkflow(current)), reflect.ValueOf(normalWorkflow(desired))
+	for i := 0; i < have.NumField(); i++ {
+		a, b := have.Field(i), want.Field(i)
+		if emptyValue(a) && emptyValue(b) || reflect.DeepEqual(a.Interface(), b.Interface()) {
+			continue
+		}
+		key, _, _ := strings.Cut(have.Type().Field(i).Tag.Get("toml"), ",")
+		if a.Kind() == reflect.Map {
+			drift = append(drift, key)
+			continue
+		}
+		drift = append(drift, fmt.Sprintf("%s %s → %s", key, driftValue(a), driftValue(b)))
+	}
+	return strings.Join(drift, ", ")
+}
+
+// driftValue renders a workflow value for driftOf
+func driftValue(v reflect.Value) string {
+	switch {
+	case emptyValue(v):
+		return "none"
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// normalWorkflow is the workflow of a daemon with defaults filled in, as older metadata left them empty
+func normalWorkflow(meta *DaemonMeta) Workflow {
+	wf := workflowOf(meta)
+	if wf.Backend == "" {
+		wf.Backend = defaultBackend
+	}
+	return wf
+}
+
+// emptyValue treats nil & empty slices or maps alike, as JSON metadata drops empty ones
+func emptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// apply carries out a step with the same primitives as invoke, rekindle & slay
+func (s alignStep) apply() error {
+	switch s.Action {
+	case alignConflict:
+		return namesakeError(s.current)
+
+	case alignStart:
+		if alive := liveNamesake(s.desired.Name); alive != nil {
+			return namesakeError(alive)
+		}
+		if _, err := refuseOverlaps(s.desired); err != nil {
+			return err
+		}
+		_, err := startDaemon(s.desired)
+		return err
+
+	case alignRekindle:
+		_, err := reviveDaemon(s.current)
+		return err
+
+	case alignRestart:
+		// refused before the current daemon is stopped, so it keeps watching
+		if _, err := refuseOverlaps(s.desired); err != nil {
+			return err
+		}
+		if _, err := stopDaemon(s.current, stopTimeoutOf(s.current)); err != nil {
+			return err
+		}
+		// the name may have been taken again while the current daemon stopped
+		if alive := liveNamesake(s.desired.Name); alive != nil {
+			return namesakeError(alive)
+		}
+		_, err := startDaemon(s.desired)
+		return err
+
+	case alignStop:
+		if _, err := stopDaemon(s.current, stopTimeoutOf(s.current)); err != nil {
+			return err
+		}
+		if keepLogsOf(s.current) {
+			if _, err := archiveDaemonFiles(s.current); err != nil {
+				return err
+			}
+		}
+		return removeDaemonFiles(s.current)
+	}
+	return nil
+}
+
+// namesakeError refuses to start a workflow over a live daemon holding its name
+func namesakeError(alive *DaemonMeta) error {
+	return horus.NewCategorizedHerror(
+		"align.apply", "spawn_error",
+		fmt.Sprintf("daemon %q is already alive with PID %d; slay it or rename the workflow", alive.Name, alive.PID),
+		nil, map[string]any{"daemon": alive.Name, "pid": alive.PID},
+	)
+}
+
+func (s alignStep) actionCell() string {
+	switch s.Action {
+	case alignStart:
+		return paint(chalk.Green, s.Action)
+	case alignRekindle:
+		return paint(chalk.Cyan, s.Action)
+	case alignRestart:
+		return paint(chalk.Yellow, s.Action)
+	default:
+		return paint(chalk.Red, s.Action)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestDriftOf(t *testing.T) {
+	desired := func() *DaemonMeta {
+		return &DaemonMeta{
+			Name:       "helix",
+			Group:      "forge",
+			WatchPaths: []string{"/w/src", "/w/docs"},
+			Run:        "make",
+			Backend:    defaultBackend,
+			Debounce:   "100ms",
+			Env:        map[string]string{"MODE": "dev"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		current func(*DaemonMeta)
+		desired func(*DaemonMeta) // nil keeps the desired daemon as is
+		want    string
+	}{
+		{
+			name:    "unchanged",
+			current: func(*DaemonMeta) {},
+		},
+		{
+			name:    "backend left empty by older metadata",
+			current: func(m *DaemonMeta) { m.Backend = "" },
+		},
+		{
+			name:    "empty & nil slices alike",
+			current: func(m *DaemonMeta) { m.Ignore = []string{} },
+		},
+		{
+			name:    "empty & nil maps alike",
+			current: func(m *DaemonMeta) { m.Env = map[string]string{} },
+			desired: func(m *DaemonMeta) { m.Env = nil },
+		},
+		{
+			name:    "map added",
+			current: func(m *DaemonMeta) { m.Env = nil },
+			want:    "env",
+		},
+		{
+			name:    "string",
+			current: func(m *DaemonMeta) { m.Debounce = "1s" },
+			want:    "debounce 1s → 100ms",
+		},
+		{
+			name:    "set to none",
+			current: func(m *DaemonMeta) { m.Restart = "always" },
+			want:    "restart always → none",
+		},
+		{
+			name:    "slice order",
+			current: func(m *DaemonMeta) { m.WatchPaths = []string{"/w/docs", "/w/src"} },
+			want:    "watch /w/docs,/w/src → /w/src,/w/docs",
+		},
+		{
+			name:    "map shows the key only",
+			current: func(m *DaemonMeta) { m.Env = map[string]string{"MODE": "prod"} },
+			want:    "env",
+		},
+		{
+			name:    "bool",
+			current: func(m *DaemonMeta) { m.ChangedFile = true },
+			want:    "changed_file true → none",
+		},
+		{
+			name:    "group first, then keys in workflow order",
+			current: func(m *DaemonMeta) { m.Group = "adhoc"; m.Debounce = ""; m.Run = "make all" },
+			want:    "group adhoc → forge, run make all → make, debounce none → 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, want := desired(), desired()
+			if tt.desired != nil {
+				tt.desired(want)
+			}
+			tt.current(current)
+			if got := driftOf(current, want); got != tt.want {
+				t.Errorf("driftOf = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
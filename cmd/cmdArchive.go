@@ -0,0 +1,404 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DanielRivasMD/domovoi"
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var archiveCmd = &cobra.Command{
+	Use:     "archive",
+	Short:   "Browse & purge the files of slain daemons",
+	Long:    helpArchive,
+	Example: exampleArchive,
+}
+
+var archiveListCmd = &cobra.Command{
+	Use:   "list " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon]")),
+	Short: "List archives, of every daemon or of one",
+
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArchivedNames,
+
+	Run: runArchiveList,
+}
+
+var archiveShowCmd = &cobra.Command{
+	Use:   "show " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon] [archive]")),
+	Short: "Show the final metadata & log of an archive, the latest by default",
+
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeArchivedNames,
+
+	Run: runArchiveShow,
+}
+
+var archivePurgeCmd = &cobra.Command{
+	Use:   "purge " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon]")),
+	Short: "Delete archives",
+
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArchivedNames,
+
+	Run: runArchivePurge,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	archiveLines     int
+	archivePurgeAll  bool
+	archiveOlderThan string
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveListCmd, archiveShowCmd, archivePurgeCmd)
+
+	archiveShowCmd.Flags().IntVarP(&archiveLines, "lines", "n", 0, "Show only the last N lines of the log (default all)")
+	archivePurgeCmd.Flags().BoolVar(&archivePurgeAll, "all", false, "Purge the archives of every daemon")
+	archivePurgeCmd.Flags().StringVar(&archiveOlderThan, "older-than", "", "Purge only archives older than this (e.g. 30d)")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpArchive = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Daemons slain with --keep-logs, or with keep_logs set, leave their log, final metadata, history & runs\n"+
+		"in ~/.lilith/archive/<name>/<timestamp>/, listed, shown & purged from here",
+)
+
+var exampleArchive = formatExample(
+	"lilith",
+	[]string{"archive", "list"},
+	[]string{"archive", "show", "helix"},
+	[]string{"archive", "show", "helix", "20250601-100000.000", "--lines", "50"},
+	[]string{"archive", "purge", "helix"},
+	[]string{"archive", "purge", "--all", "--older-than", "30d"},
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// archiveStamp names the archive of a slay, so archives of a daemon sort chronologically
+const archiveStamp = "20060102-150405.000"
+
+// archiveEntry is one ~/.lilith/archive/<name>/<timestamp>/ directory
+type archiveEntry struct {
+	Name string
+	ID   string
+	Path string
+	At   time.Time
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runArchiveList(cmd *cobra.Command, args []string) {
+	const op = "lilith.archive.list"
+
+	entries, err := listArchives(optionalArg(args))
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("listing archives"))
+
+	fmt.Printf("%-20s %-20s %-15s %-18s %s\n", "NAME", "ARCHIVE", "GROUP", "LAST EXIT", "SIZE")
+	for _, e := range entries {
+		group, exit := "-", "-"
+		if meta, err := e.meta(); err == nil {
+			group = orDash(meta.Group)
+		}
+		if rec := e.lastExit(); rec != nil {
+			exit = rec.Summary()
+		}
+		fmt.Printf("%-20s %-20s %-15s %-18s %s\n", e.Name, e.ID, group, exit, formatBytes(dirSize(e.Path)))
+	}
+}
+
+func runArchiveShow(cmd *cobra.Command, args []string) {
+	const op = "lilith.archive.show"
+
+	entries, err := listArchives(args[0])
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("listing archives"))
+	if len(entries) == 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "no archive", nil, map[string]any{"daemon": args[0]}))
+		return
+	}
+	entry := entries[len(entries)-1]
+	if len(args) == 2 {
+		found := false
+		for _, e := range entries {
+			if e.ID == args[1] {
+				entry, found = e, true
+			}
+		}
+		if !found {
+			horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "archive not found", nil, map[string]any{"daemon": args[0], "archive": args[1]}))
+		}
+	}
+
+	meta, err := entry.meta()
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading archived metadata"))
+
+	lines, _, err := readLog(filepath.Join(entry.Path, filepath.Base(meta.LogPath)), archiveLines, logFilter{}, &timeline{})
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading archived log"))
+
+	horus.CheckErr(withPager(func(w io.Writer) error {
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "daemon:  "), meta.Name)
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "group:   "), meta.Group)
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "archived:"), entry.At.Format(time.DateTime))
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "invoked: "), meta.InvokedAt.Format(time.DateTime))
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "watch:   "), strings.Join(meta.WatchPaths, "\n          "))
+		fmt.Fprintf(w, "%s %s\n", paint(chalk.Cyan, "script:  "), scriptLabel(meta))
+		if rec := entry.lastExit(); rec != nil {
+			fmt.Fprintf(w, "%s %s after %s\n", paint(chalk.Cyan, "exit:    "), rec.Summary(), rec.Duration)
+		}
+		fmt.Fprintf(w, "%s %s\n\n", paint(chalk.Cyan, "path:    "), entry.Path)
+
+		for _, l := range lines {
+			if _, err := fmt.Fprintln(w, l.text); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), horus.WithOp(op), horus.WithMessage("printing archive"))
+}
+
+func runArchivePurge(cmd *cobra.Command, args []string) {
+	const op = "lilith.archive.purge"
+
+	name := optionalArg(args)
+	if (name == "" && !archivePurgeAll) || (name != "" && archivePurgeAll) {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "must provide a daemon name or --all", nil, nil))
+	}
+	var cutoff time.Time
+	if archiveOlderThan != "" {
+		age, err := parseAge(archiveOlderThan)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("config_error"), horus.WithMessage("parsing --older-than"))
+		cutoff = time.Now().Add(-age)
+	}
+
+	entries, err := listArchives(name)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("listing archives"))
+
+	purged := 0
+	for _, e := range entries {
+		if !cutoff.IsZero() && !e.At.Before(cutoff) {
+			continue
+		}
+		horus.CheckErr(
+			os.RemoveAll(e.Path),
+			horus.WithOp(op),
+			horus.WithCategory("env_error"),
+			horus.WithMessage(fmt.Sprintf("removing %s", e.Path)),
+		)
+		// a daemon left without archives leaves no directory behind
+		_ = os.Remove(filepath.Dir(e.Path))
+		purged++
+	}
+	fmt.Printf("%s purged %d archive(s)\n", paint(chalk.Green, "OK:"), purged)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// archiveDir returns ~/.lilith/archive
+func archiveDir() string {
+	return filepath.Join(home, ".lilith", "archive")
+}
+
+// keepLogsOf reports whether slaying meta archives its files rather than deleting them,
+// as set by keep_logs in its workflow or in ~/.lilith/lilith.toml
+func keepLogsOf(meta *DaemonMeta) bool {
+	if meta.KeepLogs {
+		return true
+	}
+	settings, err := loadSettings()
+	return err == nil && settings.GetBool("keep_logs")
+}
+
+// archiveDaemonFiles moves the metadata, exit history, run ledger, run outputs & log segments of a
+// stopped daemon into a new archive, returning its path; removeDaemonFiles then finds nothing left
+func archiveDaemonFiles(meta *DaemonMeta) (string, error) {
+	const op = "daemon.archive"
+
+	dir := filepath.Join(archiveDir(), meta.Name, time.Now().Format(archiveStamp))
+	if err := domovoi.CreateDir(dir, false); err != nil {
+		return "", horus.Wrap(err, op, "creating archive directory")
+	}
+
+	files := append([]string{
+		filepath.Join(GetDaemonDir(), meta.Name+".json"),
+		historyPath(meta.Name),
+		ledgerPath(meta.Name),
+		runOutputDir(meta.Name),
+		meta.LogPath,
+	}, logSegments(meta.LogPath)...)
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(file, filepath.Join(dir, filepath.Base(file))); err != nil {
+			return "", horus.NewCategorizedHerror(op, "env_error", "archiving", err, map[string]any{"path": file, "archive": dir})
+		}
+	}
+	return dir, nil
+}
+
+// listArchives returns the archives of a daemon, or of every daemon when name is empty, oldest first
+func listArchives(name string) ([]archiveEntry, error) {
+	const op = "archive.list"
+
+	if name == ".." || strings.ContainsRune(name, filepath.Separator) {
+		return nil, horus.NewCategorizedHerror(op, "validation", "invalid daemon name", nil, map[string]any{"daemon": name})
+	}
+	names := []string{name}
+	if name == "" {
+		names = archivedNames()
+	}
+
+	var out []archiveEntry
+	for _, n := range names {
+		fis, err := os.ReadDir(filepath.Join(archiveDir(), n))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, horus.NewCategorizedHerror(op, "env_error", "reading archives", err, map[string]any{"daemon": n})
+		}
+		for _, fi := range fis {
+			at, err := time.ParseInLocation(archiveStamp, fi.Name(), time.Local)
+			if err != nil || !fi.IsDir() {
+				continue
+			}
+			out = append(out, archiveEntry{Name: n, ID: fi.Name(), Path: filepath.Join(archiveDir(), n, fi.Name()), At: at})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// archivedNames lists the daemons holding archives
+func archivedNames() []string {
+	fis, err := os.ReadDir(archiveDir())
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, fi := range fis {
+		if fi.IsDir() {
+			out = append(out, fi.Name())
+		}
+	}
+	return out
+}
+
+func completeArchivedNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, name := range archivedNames() {
+		if strings.HasPrefix(name, toComplete) {
+			out = append(out, name)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// meta reads the final metadata of the archived daemon
+func (e archiveEntry) meta() (*DaemonMeta, error) {
+	const op = "archive.meta"
+
+	path := filepath.Join(e.Path, e.Name+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "reading metadata", err, map[string]any{"path": path})
+	}
+	var meta DaemonMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "parsing metadata", err, map[string]any{"path": path})
+	}
+	return &meta, nil
+}
+
+// lastExit returns the last exit of the watcher recorded in the archive, if any
+func (e archiveEntry) lastExit() *ExitRecord {
+	history, err := readJSONLines[ExitRecord](filepath.Join(e.Path, filepath.Base(historyPath(e.Name))))
+	if err != nil || len(history) == 0 {
+		return nil
+	}
+	return &history[len(history)-1]
+}
+
+// dirSize sums the sizes of the files below dir
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// formatBytes renders a size for people, e.g. 512B, 1.5KB or 120.0MB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// optionalArg returns the first argument, empty when there is none
+func optionalArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var chronicleCmd = &cobra.Command{
+	Use:     "chronicle " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon]")),
+	Short:   "List script runs of a daemon",
+	Long:    helpChronicle,
+	Example: exampleChronicle,
+
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDaemonNames,
+
+	Run: runChronicle,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	chronicleFailed    bool
+	chronicleSucceeded bool
+	chronicleLimit     int
+	chronicleRun       string
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(chronicleCmd)
+
+	chronicleCmd.Flags().BoolVar(&chronicleFailed, "failed", false, "Only list failed runs")
+	chronicleCmd.Flags().BoolVar(&chronicleSucceeded, "succeeded", false, "Only list successful runs")
+	chronicleCmd.Flags().IntVarP(&chronicleLimit, "limit", "n", 20, "Number of most recent runs to list (0 for all)")
+	chronicleCmd.Flags().StringVar(&chronicleRun, "run", "", "Show the captured output of a run ID, or `last`")
+
+	chronicleCmd.MarkFlagsMutuallyExclusive("failed", "succeeded")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpChronicle = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"List every run of a daemon's script: when it started, which files triggered it, how long it took & its exit code\n"+
+		"Pass --run to display the output captured for a single run",
+)
+
+var exampleChronicle = formatExample(
+	"lilith",
+	[]string{"chronicle", "helix"},
+	[]string{"chronicle", "helix", "--failed"},
+	[]string{"chronicle", "helix", "--run", "last"},
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runChronicle(cmd *cobra.Command, args []string) {
+	const op = "lilith.chronicle"
+	name := args[0]
+
+	runs, err := loadRuns(name)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading runs for %q", name)))
+	// a daemon yet to run has metadata but no ledger; one with neither does not exist
+	if _, err := os.Stat(filepath.Join(GetDaemonDir(), name+".json")); len(runs) == 0 && os.IsNotExist(err) {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "daemon not found", nil, map[string]any{"daemon": name}))
+	}
+
+	if chronicleRun != "" {
+		showRun(name, runs, chronicleRun)
+		return
+	}
+
+	var selected []RunRecord
+	for _, r := range runs {
+		switch {
+		case chronicleFailed && r.Succeeded(), chronicleSucceeded && !r.Succeeded():
+			continue
+		}
+		selected = append(selected, r)
+	}
+	if chronicleLimit > 0 && len(selected) > chronicleLimit {
+		selected = selected[len(selected)-chronicleLimit:]
+	}
+
+	fmt.Printf(
+		"%-25s %-20s %-10s %-6s %s\n",
+		"ID", "STARTED", "DURATION", "EXIT", "TRIGGER",
+	)
+	for _, r := range selected {
+		exit := strconv.Itoa(r.ExitCode)
+		if r.Signal != "" {
+			exit = r.Signal
+		}
+		exitCell := fmt.Sprintf("%-6s", exit)
+		if r.Succeeded() {
+			exitCell = paint(chalk.Green, exitCell)
+		} else {
+			exitCell = paint(chalk.Red, exitCell)
+		}
+		fmt.Printf(
+			"%-25s %-20s %-10s %s %s\n",
+			r.ID, r.StartedAt.Format("2006-01-02 15:04:05"), r.Duration, exitCell, describeCause(&r),
+		)
+	}
+}
+
+// showRun prints a run's ledger entry followed by its captured output
+func showRun(name string, runs []RunRecord, id string) {
+	const op = "lilith.chronicle"
+
+	var rec *RunRecord
+	for i := range runs {
+		if runs[i].ID == id || (id == "last" && i == len(runs)-1) {
+			rec = &runs[i]
+		}
+	}
+	if rec == nil {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "run not found", nil, map[string]any{"daemon": name, "run": id}))
+		return
+	}
+
+	fmt.Printf("%s %s\n", paint(chalk.Cyan, "run:     "), rec.ID)
+	fmt.Printf("%s %s\n", paint(chalk.Cyan, "started: "), rec.StartedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("%s %s\n", paint(chalk.Cyan, "duration:"), rec.Duration)
+	fmt.Printf("%s %d %s\n", paint(chalk.Cyan, "exit:    "), rec.ExitCode, rec.Signal)
+	if rec.Upstream != "" {
+		fmt.Printf("%s %s (depth %d)\n", paint(chalk.Cyan, "upstream:"), rec.Upstream, rec.Depth)
+	}
+	if len(rec.Trigger) > 0 {
+		fmt.Printf("%s %s\n", paint(chalk.Cyan, "trigger: "), strings.Join(rec.Trigger, "\n          "))
+	}
+	fmt.Println()
+
+	f, err := os.Open(runOutputPath(name, rec.ID))
+	if os.IsNotExist(err) {
+		fmt.Println(chalk.Dim.TextStyle(fmt.Sprintf("output pruned, only the last %d runs are kept", maxRunOutputs)))
+		return
+	}
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("opening run output"))
+	defer f.Close()
+
+	_, err = io.Copy(os.Stdout, f)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("printing run output"))
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -20,8 +20,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"syscall"
+	"time"
 
 	"github.com/DanielRivasMD/horus"
 	"github.com/spf13/cobra"
@@ -49,6 +49,7 @@ func init() {
 
 	freezeCmd.Flags().String("group", "", "Freeze all daemons belonging to a specific group")
 	freezeCmd.Flags().Bool("all", false, "Freeze all running daemons")
+	addOutputFlag(freezeCmd)
 
 	horus.CheckErr(freezeCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups), horus.WithOp("freeze.init"), horus.WithMessage("registering config completion"))
 }
@@ -58,7 +59,7 @@ func init() {
 var helpFreeze = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
-	"Pause daemon execution using SIGSTOP, until resumed manually",
+	"Pause daemon execution using SIGSTOP, until resumed with thaw",
 )
 
 var exampleFreeze = formatExample(
@@ -79,11 +80,9 @@ func RunFreeze(cmd *cobra.Command, args []string) {
 	switch {
 	case all:
 		freezeAllDaemons()
-		return
 	case group != "":
 		freezeGroupDaemons(group)
-		return
-	default:
+	case len(args) == 1:
 		// Single daemon freeze
 		name := args[0]
 
@@ -91,25 +90,49 @@ func RunFreeze(cmd *cobra.Command, args []string) {
 		meta, err := loadMeta(name)
 		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading metadata for %q", name)))
 
-		// 2) Find and pause process
-		proc, err := os.FindProcess(meta.PID)
-		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("finding process"))
-		horus.CheckErr(proc.Signal(syscall.SIGSTOP), horus.WithOp(op), horus.WithMessage("sending SIGSTOP"))
-
-		// 3) Confirmation
-		fmt.Printf("%s froze daemon %q\n", chalk.Green.Color("OK:"), name)
+		// 2) Pause process & record the intent & confirm it
+		freezeAndReport(meta)
+	default:
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "must provide a daemon name or --all / --group", nil, nil))
 	}
+	finishReports(op)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// freezeDaemon stops the daemon's process group & marks the pause as intentional.
+// Both happen on a fresh copy of the metadata under the lock, keeping what a concurrent vigil restart recorded
+func freezeDaemon(meta *DaemonMeta) error {
+	var sigErr error
+	fresh, err := updateMeta(meta.Name, func(current *DaemonMeta) bool {
+		if sigErr = signalDaemon(current, syscall.SIGSTOP); sigErr != nil {
+			return false
+		}
+		current.Frozen = true
+		current.FrozenAt = time.Now()
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	*meta = *fresh
+	return sigErr
+}
+
+// freezeAndReport freezes a daemon, reporting a failure rather than exiting so the others proceed
+func freezeAndReport(meta *DaemonMeta) {
+	if err := freezeDaemon(meta); err != nil {
+		reportFailure(meta, err, fmt.Sprintf("freezing %q", meta.Name))
+		return
+	}
+	report(meta, "frozen", fmt.Sprintf("froze daemon %q", meta.Name))
+}
+
 func freezeGroupDaemons(group string) {
 	files := mustListDaemonMetaFiles()
 	for _, path := range files {
 		if matchesGroup(path, group) {
-			meta := mustLoadMeta(path)
-			_ = sendSignal(meta.PID, syscall.SIGSTOP)
-			fmt.Printf("%s froze daemon %q\n", chalk.Green.Color("OK:"), meta.Name)
+			freezeAndReport(mustLoadMeta(path))
 		}
 	}
 }
@@ -117,9 +140,7 @@ func freezeGroupDaemons(group string) {
 func freezeAllDaemons() {
 	files := mustListDaemonMetaFiles()
 	for _, path := range files {
-		meta := mustLoadMeta(path)
-		_ = sendSignal(meta.PID, syscall.SIGSTOP)
-		fmt.Printf("%s froze daemon %q\n", chalk.Green.Color("OK:"), meta.Name)
+		freezeAndReport(mustLoadMeta(path))
 	}
 }
 
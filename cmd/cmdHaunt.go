@@ -0,0 +1,533 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var hauntCmd = &cobra.Command{
+	Use:    "haunt",
+	Hidden: true,
+	Short:  "Watch a path & execute a script on change",
+	Long:   helpHaunt,
+
+	Args: cobra.NoArgs,
+
+	Run: runHaunt,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	hauntWatch    []string
+	hauntName     string
+	hauntDebounce time.Duration
+	hauntPoll     bool
+	hauntInterval time.Duration
+	hauntIgnore   []string
+	hauntExts     []string
+	hauntEvents   []string
+	hauntList     bool
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(hauntCmd)
+
+	hauntCmd.Flags().StringArrayVar(&hauntWatch, "watch", nil, "Path to watch recursively, repeatable")
+	hauntCmd.Flags().StringVar(&hauntName, "name", "", "Daemon whose script is executed on change")
+	hauntCmd.Flags().DurationVar(&hauntDebounce, "debounce", defaultDebounce, "Quiet period before executing")
+	hauntCmd.Flags().BoolVar(&hauntPoll, "poll", false, "Detect changes by polling instead of filesystem events")
+	hauntCmd.Flags().DurationVar(&hauntInterval, "interval", defaultPollInterval, "Polling interval used with --poll")
+	hauntCmd.Flags().StringArrayVar(&hauntIgnore, "ignore", nil, "Pattern of paths to ignore, repeatable")
+	hauntCmd.Flags().StringSliceVar(&hauntExts, "extensions", nil, "Only react to files with these extensions")
+	hauntCmd.Flags().StringSliceVar(&hauntEvents, "events", nil, "Only react to these changes: "+strings.Join(eventKinds, ", "))
+	hauntCmd.Flags().BoolVar(&hauntList, "list", false, "Print the files passing the filters & exit, for backends without their own")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpHaunt = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"In-process watcher spawned by invoke & rekindle for the native & poll backends\n"+
+		"Executes the daemon's rite once at startup & again after every debounced change\n"+
+		"Changes are filtered by --ignore, --extensions & --events before they count",
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	// defaultDebounce is the quiet period the watcher waits for after the last change
+	defaultDebounce = 100 * time.Millisecond
+	// defaultPollInterval is how often the poll backend rescans the watch tree
+	defaultPollInterval = 2 * time.Second
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runHaunt(cmd *cobra.Command, args []string) {
+	const op = "lilith.haunt"
+
+	if len(hauntWatch) == 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "spawn_error", "`--watch` is required", nil, nil))
+	}
+
+	filter, err := newWatchFilter(hauntWatch, hauntIgnore, hauntExts, hauntEvents)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("building filters"))
+
+	if hauntList {
+		horus.CheckErr(listWatched(hauntWatch, filter), horus.WithOp(op), horus.WithMessage("listing watched files"))
+		return
+	}
+
+	horus.CheckEmpty(hauntName, "`--name` is required", horus.WithOp(op), horus.WithCategory("spawn_error"))
+
+	var src source
+	if hauntPoll {
+		src, err = newPoller(hauntWatch, hauntInterval, filter)
+	} else {
+		src, err = newHaunter(hauntWatch, filter)
+	}
+	horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("env_error"), horus.WithMessage("starting watcher"))
+	defer src.Close()
+
+	horus.CheckErr(hauntLoop(src, hauntName, hauntDebounce), horus.WithOp(op), horus.WithMessage("watching"))
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// source delivers changed paths from either filesystem events or polling
+type source interface {
+	Changes() <-chan string
+	Errors() <-chan error
+	Close() error
+}
+
+// hauntLoop executes the daemon's rite once, then again after each burst of changes settles for debounce.
+// Changes arriving during a run are coalesced into a single follow-up run
+func hauntLoop(src source, name string, debounce time.Duration) error {
+	const op = "haunt.loop"
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+
+	done := make(chan struct{}, 1)
+	timer := time.NewTimer(0) // fire immediately for the initial run
+	pending := false
+	var (
+		running *exec.Cmd
+		changed []string // paths collected since the last run started
+	)
+
+	start := func() {
+		pending = false
+		c, err := riteCommand(name, trimTrigger(changed))
+		changed = nil
+		if err != nil {
+			fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "building rite"))
+			return
+		}
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Start(); err != nil {
+			fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "starting rite"))
+			return
+		}
+		running = c
+		go func() {
+			// the rite reports its own failures
+			_ = c.Wait()
+			done <- struct{}{}
+		}()
+	}
+
+	for {
+		select {
+		case path, ok := <-src.Changes():
+			if !ok {
+				return nil
+			}
+			changed = append(changed, path)
+			timer.Reset(debounce)
+
+		case err, ok := <-src.Errors():
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+
+		case <-timer.C:
+			if running != nil {
+				pending = true
+				continue
+			}
+			start()
+
+		case <-done:
+			running = nil
+			if pending {
+				start()
+			}
+
+		case sig := <-sigs:
+			// take the current run down with the watcher, as watchexec did
+			if running != nil {
+				_ = running.Process.Signal(sig)
+				<-done
+			}
+			return nil
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// listWatched prints every file below the roots that passes the filter, skipping ignored directories
+func listWatched(roots []string, filter *watchFilter) error {
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path != root {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if filter.prunes(path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filter.allowsPath(path) {
+				fmt.Println(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// haunter wraps fsnotify with recursive registration & single-file filtering
+type haunter struct {
+	fw      *fsnotify.Watcher
+	dirs    []string        // cleaned directory roots, watched recursively
+	files   map[string]bool // cleaned single-file roots
+	filter  *watchFilter
+	changes chan string
+	errs    chan error
+}
+
+// newHaunter registers every root, descending into each subdirectory.
+// A single file is watched through its parent so that editors replacing it are still seen
+func newHaunter(roots []string, filter *watchFilter) (*haunter, error) {
+	const op = "haunt.new"
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "creating fsnotify watcher", err, nil)
+	}
+
+	h := &haunter{
+		fw:      fw,
+		files:   map[string]bool{},
+		filter:  filter,
+		changes: make(chan string),
+		errs:    make(chan error),
+	}
+	for _, root := range roots {
+		if err := h.addRoot(filepath.Clean(root)); err != nil {
+			_ = fw.Close()
+			return nil, err
+		}
+	}
+
+	go h.forward()
+	return h, nil
+}
+
+func (h *haunter) addRoot(root string) error {
+	const op = "haunt.addRoot"
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "inspecting watch path", err, map[string]any{"watch": root})
+	}
+	if info.IsDir() {
+		h.dirs = append(h.dirs, root)
+		return h.addTree(root)
+	}
+	h.files[root] = true
+	if err := h.fw.Add(filepath.Dir(root)); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "watching parent directory", err, map[string]any{"watch": root})
+	}
+	return nil
+}
+
+// inTree reports whether path lies within one of the directory roots
+func (h *haunter) inTree(path string) bool {
+	for _, dir := range h.dirs {
+		if within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *haunter) Changes() <-chan string { return h.changes }
+func (h *haunter) Errors() <-chan error   { return h.errs }
+func (h *haunter) Close() error           { return h.fw.Close() }
+
+// forward filters raw fsnotify events, registering directories created after startup
+func (h *haunter) forward() {
+	const op = "haunt.forward"
+	defer close(h.changes)
+	defer close(h.errs)
+
+	for {
+		select {
+		case ev, ok := <-h.fw.Events:
+			if !ok {
+				return
+			}
+			// parents of single-file roots report their siblings too
+			tree := h.inTree(ev.Name)
+			if !tree && !h.files[filepath.Clean(ev.Name)] {
+				continue
+			}
+			if tree && ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := h.addTree(ev.Name); err != nil {
+						h.errs <- err
+					}
+				}
+			}
+			if !h.filter.allows(ev.Name, eventKind(ev.Op)) {
+				continue
+			}
+			h.changes <- ev.Name
+
+		case err, ok := <-h.fw.Errors:
+			if !ok {
+				return
+			}
+			h.errs <- horus.NewCategorizedHerror(op, "watch_error", "receiving fsnotify error", err, nil)
+		}
+	}
+}
+
+// eventKind names an fsnotify operation after the `events` key; attribute changes count as modify
+func eventKind(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return eventCreate
+	case op.Has(fsnotify.Remove):
+		return eventRemove
+	case op.Has(fsnotify.Rename):
+		return eventRename
+	default:
+		return eventModify
+	}
+}
+
+// addTree registers dir & all directories below it, except ignored ones
+func (h *haunter) addTree(dir string) error {
+	const op = "haunt.addTree"
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// directories may vanish between the event & the walk
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return horus.NewCategorizedHerror(op, "env_error", "walking watch tree", err, map[string]any{"path": path})
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if h.filter.prunes(path) {
+			return filepath.SkipDir
+		}
+		if err := h.fw.Add(path); err != nil {
+			return horus.NewCategorizedHerror(op, "env_error", "adding watch", err, map[string]any{"path": path})
+		}
+		return nil
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// poller rescans the watch tree on an interval, comparing size & modification time
+type poller struct {
+	roots    []string
+	interval time.Duration
+	filter   *watchFilter
+	changes  chan string
+	errs     chan error
+	stop     chan struct{}
+}
+
+// stamp is what the poller remembers about each path
+type stamp struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+func newPoller(roots []string, interval time.Duration, filter *watchFilter) (*poller, error) {
+	const op = "haunt.newPoller"
+
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	p := &poller{
+		roots:    roots,
+		interval: interval,
+		filter:   filter,
+		changes:  make(chan string),
+		errs:     make(chan error),
+		stop:     make(chan struct{}),
+	}
+
+	snap, err := p.snapshot()
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "scanning watch path", err, map[string]any{"watch": strings.Join(roots, ", ")})
+	}
+
+	go p.run(snap)
+	return p, nil
+}
+
+func (p *poller) Changes() <-chan string { return p.changes }
+func (p *poller) Errors() <-chan error   { return p.errs }
+func (p *poller) Close() error           { close(p.stop); return nil }
+
+// run diffs consecutive snapshots, reporting every created, modified or removed path
+func (p *poller) run(prev map[string]stamp) {
+	const op = "haunt.poll"
+	defer close(p.changes)
+	defer close(p.errs)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		next, err := p.snapshot()
+		if err != nil {
+			select {
+			case p.errs <- horus.NewCategorizedHerror(op, "watch_error", "scanning watch path", err, map[string]any{"watch": strings.Join(p.roots, ", ")}):
+			case <-p.stop:
+				return
+			}
+			continue
+		}
+
+		for path, kind := range diffSnapshots(prev, next) {
+			if !p.filter.allows(path, kind) {
+				continue
+			}
+			select {
+			case p.changes <- path:
+			case <-p.stop:
+				return
+			}
+		}
+		prev = next
+	}
+}
+
+// snapshot records every path below the roots; vanished entries mid-walk & ignored directories are skipped
+func (p *poller) snapshot() (map[string]stamp, error) {
+	snap := map[string]stamp{}
+	for _, root := range p.roots {
+		root = filepath.Clean(root)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path != root {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() && p.filter.prunes(path) {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			snap[path] = stamp{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// diffSnapshots returns the paths that differ between two snapshots with the kind of change;
+// polling cannot tell renames apart, which surface as a remove & a create.
+// Directories touched by changes to their entries are left out, matching fsnotify
+func diffSnapshots(prev, next map[string]stamp) map[string]string {
+	changed := map[string]string{}
+	for path, st := range next {
+		old, ok := prev[path]
+		switch {
+		case !ok:
+			changed[path] = eventCreate
+		case old != st && !st.mode.IsDir():
+			changed[path] = eventModify
+		}
+	}
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			changed[path] = eventRemove
+		}
+	}
+	return changed
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
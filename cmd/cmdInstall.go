@@ -117,7 +117,7 @@ func createConfig(verbose bool) {
 		}
 		defaultToml := `[workflows.dummy]
 watch = "~/Downloads"
-script = "echo 'downloaded'"` + "\n"
+run = "echo 'downloaded'"` + "\n"
 		if writeErr := os.WriteFile(path, []byte(defaultToml), 0644); writeErr != nil {
 			horus.CheckErr(writeErr,
 				horus.WithOp(op),
@@ -20,7 +20,9 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,7 +30,7 @@ import (
 	"github.com/DanielRivasMD/domovoi"
 	"github.com/DanielRivasMD/horus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 	"github.com/ttacon/chalk"
 )
 
@@ -47,14 +49,44 @@ var invokeCmd = &cobra.Command{
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var (
-	ConfigName string // workflow key
-	DaemonName string // instance name, defaults to configName
-	WatchDir   string
-	ScriptPath string
-	LogName    string
-	GroupName  string // derived from TOML filename
+	ConfigName    string // workflow key
+	DaemonName    string // instance name, defaults to configName
+	WatchPaths    []string
+	ScriptPath    string
+	RunBody       string // inline script, instead of ScriptPath
+	Interpreter   string
+	ScriptArgs    []string
+	WorkDir       string   // cwd of watcher & script
+	EnvVars       []string // KEY=VALUE, over the workflow env
+	EnvFile       string
+	ChangedFile   bool // write changed paths to LILITH_CHANGED_FILE
+	LogName       string
+	LogFormat     string // text or json records
+	LogMaxSize    string // rotate the log beyond this size
+	LogMaxFiles   string // rotated segments kept
+	LogMaxAge     string // age beyond which segments are removed
+	LogCompress   bool   // gzip rotated segments
+	KeepLogs      bool   // slay archives the log rather than deleting it
+	Backend       string // watcher backend, see watchers
+	Debounce      string // quiet period before a run
+	Ignore        []string
+	Extensions    []string
+	Events        []string
+	StopTimeout   string // grace period before slay escalates to SIGKILL
+	Restart       string // vigil restart policy
+	MaxRestarts   string // vigil restarts allowed per window
+	RestartWindow string // vigil sliding window for MaxRestarts
+	GroupName     string // derived from TOML filename
+	SaveFile      string // TOML file receiving an ad hoc invocation as a workflow
+	InvokeAll     bool   // start every workflow of every config file
+	AllowOverlap  bool   // start despite live daemons watching the same or nested paths
+
+	configured Workflow // table applied by --config, for keys without a flag
 )
 
+// adHocGroup groups daemons invoked without --config or --save
+const adHocGroup = "adhoc"
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func init() {
@@ -62,12 +94,47 @@ func init() {
 
 	invokeCmd.Flags().StringVarP(&ConfigName, "config", "c", "", "Workflow to apply")
 	invokeCmd.Flags().StringVarP(&DaemonName, "name", "n", "", "Unique daemon name (defaults to --config)")
-	invokeCmd.Flags().StringVarP(&GroupName, "group", "g", "", "Watcher group name (overrides TOML)")
-	invokeCmd.Flags().StringVarP(&WatchDir, "watch", "w", "", "Directory to watch")
+	invokeCmd.Flags().StringVarP(&GroupName, "group", "g", "", "Watcher group name (overrides TOML, defaults to "+adHocGroup+" without --config); alone, starts every workflow of the group")
+	invokeCmd.Flags().StringArrayVarP(&WatchPaths, "watch", "w", nil, "Path to watch, repeatable")
 	invokeCmd.Flags().StringVarP(&ScriptPath, "script", "s", "", "Script to execute on change")
+	invokeCmd.Flags().StringVar(&RunBody, "run", "", "Inline script to execute on change, instead of --script")
+	invokeCmd.Flags().StringVar(&Interpreter, "interpreter", "", "Interpreter of the script: "+strings.Join(interpreters, ", ")+" (default "+defaultInterpreter+")")
+	invokeCmd.Flags().StringArrayVar(&ScriptArgs, "args", nil, "Argument passed to the script, repeatable")
+	invokeCmd.Flags().StringVar(&WorkDir, "cwd", "", "Working directory of the script")
+	invokeCmd.Flags().StringArrayVar(&EnvVars, "env", nil, "Environment variable KEY=VALUE for the script, repeatable")
+	invokeCmd.Flags().StringVar(&EnvFile, "env-file", "", "File of KEY=VALUE lines loaded into the script environment")
+	invokeCmd.Flags().BoolVar(&ChangedFile, "changed-file", false, "Also list changed paths in the file named by LILITH_CHANGED_FILE")
 	invokeCmd.Flags().StringVarP(&LogName, "log", "l", "", "Name for log file (no `.log` extension)")
+	invokeCmd.Flags().StringVar(&LogFormat, "log-format", "", "Format of the log records: "+strings.Join(logFormats, ", ")+" (default "+logFormatText+")")
+	invokeCmd.Flags().StringVar(&LogMaxSize, "log-max-size", "", "Rotate the log once it would outgrow this size (e.g. 100MB)")
+	invokeCmd.Flags().StringVar(&LogMaxFiles, "log-max-files", "", "Rotated log segments kept (default "+strconv.Itoa(defaultLogMaxFiles)+")")
+	invokeCmd.Flags().StringVar(&LogMaxAge, "log-max-age", "", "Remove rotated log segments older than this (e.g. 7d)")
+	invokeCmd.Flags().BoolVar(&LogCompress, "log-compress", false, "Gzip rotated log segments")
+	invokeCmd.Flags().BoolVar(&KeepLogs, "keep-logs", false, "Archive the log & metadata when slain instead of deleting them")
+	invokeCmd.Flags().StringVarP(&Backend, "backend", "b", "", "Watcher backend: "+strings.Join(backendNames(), ", "))
+	invokeCmd.Flags().StringVar(&Debounce, "debounce", "", "Quiet period after the last change before running (e.g. 500ms)")
+	invokeCmd.Flags().StringArrayVar(&Ignore, "ignore", nil, "Path pattern never triggering a run, repeatable (e.g. '*.tmp', '.git/**')")
+	invokeCmd.Flags().StringSliceVar(&Extensions, "extensions", nil, "Only files with these extensions trigger a run (e.g. go,toml)")
+	invokeCmd.Flags().StringSliceVar(&Events, "events", nil, "Only these changes trigger a run: "+strings.Join(eventKinds, ", "))
+	invokeCmd.Flags().StringVar(&StopTimeout, "stop-timeout", "", "Grace period before slay escalates to SIGKILL (e.g. 10s)")
+	invokeCmd.Flags().StringVar(&Restart, "restart", "", "Restart policy applied by vigil: always, on-failure, never")
+	invokeCmd.Flags().StringVar(&MaxRestarts, "max-restarts", "", "Restarts vigil allows within --restart-window")
+	invokeCmd.Flags().StringVar(&RestartWindow, "restart-window", "", "Sliding window for --max-restarts (e.g. 10m)")
+	invokeCmd.Flags().StringVar(&SaveFile, "save", "", "Append the invocation as a workflow to a TOML file (bare names go to ~/.lilith/config)")
+
+	invokeCmd.Flags().BoolVar(&InvokeAll, "all", false, "Start every workflow of every config file")
+	invokeCmd.Flags().BoolVar(&AllowOverlap, "allow-overlap", false, "Start even when another daemon watches the same, an enclosing or a nested path")
+	addOutputFlag(invokeCmd)
+
+	invokeCmd.MarkFlagsMutuallyExclusive("script", "run")
+	invokeCmd.MarkFlagsMutuallyExclusive("config", "save")
+	invokeCmd.MarkFlagsMutuallyExclusive("config", "all")
+	invokeCmd.MarkFlagsMutuallyExclusive("group", "all")
 
 	horus.CheckErr(invokeCmd.RegisterFlagCompletionFunc("config", completeWorkflowNames), horus.WithOp("invoke.init"), horus.WithMessage("registering config completion"))
+	horus.CheckErr(invokeCmd.RegisterFlagCompletionFunc("backend", completeBackends), horus.WithOp("invoke.init"), horus.WithMessage("registering backend completion"))
+	horus.CheckErr(invokeCmd.RegisterFlagCompletionFunc("interpreter", completeInterpreters), horus.WithOp("invoke.init"), horus.WithMessage("registering interpreter completion"))
+	horus.CheckErr(invokeCmd.RegisterFlagCompletionFunc("log-format", completeLogFormats), horus.WithOp("invoke.init"), horus.WithMessage("registering log format completion"))
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -76,6 +143,9 @@ var helpInvoke = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
 	"Spawn daemon process for the specified directory & execute the configured script on change\n"+
+		"Either apply a workflow with --config, or describe the daemon with --name, --watch & --script or --run\n"+
+		"--group alone starts every workflow of that config file, --all those of every file, skipping daemons already alive\n"+
+		"Pass --save to keep such an invocation as a workflow\n"+
 		"Metadata is persistent for summoning the daemon",
 )
 
@@ -88,6 +158,15 @@ var exampleInvoke = formatExample(
 		"--script", "helix.sh",
 		"--log", "helix",
 	},
+	[]string{"invoke", "--config", "goku", "--backend", "poll"},
+	[]string{
+		"invoke", "--name", "helix",
+		"--watch", "~/src/helix",
+		"--script", "helix.sh",
+		"--save", "forge.toml",
+	},
+	[]string{"invoke", "--group", "<forge>"},
+	[]string{"invoke", "--all"},
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -95,20 +174,19 @@ var exampleInvoke = formatExample(
 func PreInvoke(cmd *cobra.Command, args []string) error {
 	const op = "lilith.invoke.pre"
 
-	home, err := domovoi.FindHome(verbose)
-	horus.CheckErr(
-		err,
-		horus.WithOp(op),
-		horus.WithCategory("env_error"),
-		horus.WithMessage("getting home directory"),
-	)
-	cfgDir := filepath.Join(home, ".lilith", "config")
+	// whole groups are read straight from the config files
+	if bulkInvoke(cmd) {
+		horus.CheckErr(refuseBulkFlags(cmd), horus.WithOp(op), horus.WithMessage("starting workflows as configured"))
+		return nil
+	}
 
-	var (
-		foundV      *viper.Viper
-		cfgFileUsed string
-	)
-	fis, err := domovoi.ReadDir(cfgDir, verbose)
+	// without a workflow, flags alone describe the daemon
+	if ConfigName == "" {
+		preInvokeAdHoc(cmd)
+		return nil
+	}
+
+	wf, cfgFileUsed, err := findWorkflow(ConfigName)
 	horus.CheckErr(
 		err,
 		horus.WithOp(op),
@@ -116,26 +194,9 @@ func PreInvoke(cmd *cobra.Command, args []string) error {
 		horus.WithMessage("reading config dir"),
 	)
 
-	for _, fi := range fis {
-		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".toml") {
-			continue
-		}
-		path := filepath.Join(cfgDir, fi.Name())
-		v := viper.New()
-		v.SetConfigFile(path)
-		if err := v.ReadInConfig(); err != nil {
-			continue
-		}
-		if v.IsSet("workflows." + ConfigName) {
-			foundV = v
-			cfgFileUsed = path
-			break
-		}
-	}
-
-	if foundV == nil {
+	if wf == nil {
 		horus.CheckErr(
-			fmt.Errorf("workflow %q not found in %s/*.toml", ConfigName, cfgDir),
+			fmt.Errorf("workflow %q not found in %s/*.toml", ConfigName, configDir()),
 			horus.WithOp(op),
 			horus.WithMessage("could not find named workflow in config directory"),
 			horus.WithCategory("config_error"),
@@ -152,18 +213,59 @@ func PreInvoke(cmd *cobra.Command, args []string) error {
 		)
 	}
 
-	base := filepath.Base(cfgFileUsed)
-	GroupName = strings.TrimSuffix(base, filepath.Ext(base))
-	horus.CheckErr(
-		cmd.Flags().Set("group", GroupName),
-		horus.WithOp(op),
-		horus.WithMessage("setting default --group from TOML filename"),
-		horus.WithCategory("config_error"),
-	)
+	if !cmd.Flags().Changed("group") {
+		GroupName = groupOf(cfgFileUsed)
+		horus.CheckErr(
+			cmd.Flags().Set("group", GroupName),
+			horus.WithOp(op),
+			horus.WithMessage("setting default --group from TOML filename"),
+			horus.WithCategory("config_error"),
+		)
+	}
 
-	wf := foundV.Sub("workflows." + ConfigName)
-	BindFlag(cmd, "watch", &WatchDir, wf)
-	BindFlag(cmd, "script", &ScriptPath, wf)
+	configured, err = workflowFrom(wf)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading workflow"))
+	horus.CheckErr(checkDependencyCycle(ConfigName), horus.WithOp(op), horus.WithMessage("checking depends_on"))
+	configured.Env, err = workflowEnv(cfgFileUsed, ConfigName)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading workflow env"))
+
+	BindSliceFlag(cmd, "watch", &WatchPaths, wf)
+	// a script or body given on the command line replaces either key of the workflow
+	if !cmd.Flags().Changed("run") {
+		BindFlag(cmd, "script", &ScriptPath, wf)
+	}
+	if !cmd.Flags().Changed("script") {
+		BindFlag(cmd, "run", &RunBody, wf)
+	}
+	BindFlag(cmd, "interpreter", &Interpreter, wf)
+	BindSliceFlag(cmd, "args", &ScriptArgs, wf)
+	BindFlag(cmd, "cwd", &WorkDir, wf)
+	BindFlag(cmd, "env-file", &EnvFile, wf)
+	BindFlag(cmd, "log-format", &LogFormat, wf)
+	BindFlag(cmd, "log-max-size", &LogMaxSize, wf)
+	BindFlag(cmd, "log-max-files", &LogMaxFiles, wf)
+	BindFlag(cmd, "log-max-age", &LogMaxAge, wf)
+	BindFlag(cmd, "backend", &Backend, wf)
+	BindFlag(cmd, "debounce", &Debounce, wf)
+	BindSliceFlag(cmd, "ignore", &Ignore, wf)
+	BindSliceFlag(cmd, "extensions", &Extensions, wf)
+	BindSliceFlag(cmd, "events", &Events, wf)
+	BindFlag(cmd, "stop-timeout", &StopTimeout, wf)
+	BindFlag(cmd, "restart", &Restart, wf)
+	BindFlag(cmd, "max-restarts", &MaxRestarts, wf)
+	BindFlag(cmd, "restart-window", &RestartWindow, wf)
+	if !cmd.Flags().Changed("allow-overlap") && wf.IsSet("allow_overlap") {
+		AllowOverlap = wf.GetBool("allow_overlap")
+	}
+	if !cmd.Flags().Changed("changed-file") && wf.IsSet("changed_file") {
+		ChangedFile = wf.GetBool("changed_file")
+	}
+	if !cmd.Flags().Changed("log-compress") && wf.IsSet("log_compress") {
+		LogCompress = wf.GetBool("log_compress")
+	}
+	if !cmd.Flags().Changed("keep-logs") && wf.IsSet("keep_logs") {
+		KeepLogs = wf.GetBool("keep_logs")
+	}
 
 	if !cmd.Flags().Changed("log") {
 		LogName = ConfigName
@@ -178,21 +280,67 @@ func PreInvoke(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-////////////////////////////////////////////////////////////////////////////////////////////////////
-
-func RunInvoke(cmd *cobra.Command, args []string) {
-	const op = "lilith.invoke"
+// preInvokeAdHoc fills the defaults of a daemon described on the command line.
+// With --save, the workflow must not exist yet & its group follows the file it is saved to
+func preInvokeAdHoc(cmd *cobra.Command) {
+	const op = "lilith.invoke.pre"
 
 	horus.CheckEmpty(
-		WatchDir,
-		"`--watch` is required",
+		DaemonName,
+		"`--name` is required without `--config`",
 		horus.WithOp(op),
-		horus.WithMessage("provide a directory to watch"),
+		horus.WithMessage("provide a daemon name"),
 		horus.WithCategory("spawn_error"),
 	)
+
+	if SaveFile != "" {
+		path, err := resolveConfigPath(SaveFile)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("env_error"), horus.WithMessage("resolving --save path"))
+		SaveFile = path
+
+		_, definedIn, err := findWorkflow(DaemonName)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("env_error"), horus.WithMessage("reading config dir"))
+		if definedIn != "" {
+			horus.CheckErr(horus.NewCategorizedHerror(
+				op, "config_error", "workflow already defined, invoke it with --config", nil,
+				map[string]any{"workflow": DaemonName, "file": definedIn},
+			))
+		}
+	}
+
+	if !cmd.Flags().Changed("group") {
+		GroupName = adHocGroup
+		if SaveFile != "" {
+			GroupName = groupOf(SaveFile)
+		}
+	}
+
+	if !cmd.Flags().Changed("log") {
+		LogName = DaemonName
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func RunInvoke(cmd *cobra.Command, args []string) {
+	const op = "lilith.invoke"
+
+	if bulkInvoke(cmd) {
+		invokeWorkflows(GroupName)
+		return
+	}
+
+	if len(WatchPaths) == 0 {
+		horus.CheckErr(
+			fmt.Errorf("`--watch` is required"),
+			horus.WithOp(op),
+			horus.WithMessage("provide a path to watch"),
+			horus.WithCategory("spawn_error"),
+		)
+	}
 	horus.CheckEmpty(
-		ScriptPath,
-		"`--script` is required",
+		ScriptPath+RunBody,
+		"`--script` or `--run` is required",
 		horus.WithOp(op),
 		horus.WithMessage("provide a script to run"),
 		horus.WithCategory("spawn_error"),
@@ -205,32 +353,63 @@ func RunInvoke(cmd *cobra.Command, args []string) {
 		horus.WithCategory("spawn_error"),
 	)
 
-	WatchDir = mustExpand(WatchDir, "--watch")
-	ScriptPath = mustExpand(ScriptPath, "--script")
+	maxRestarts := 0
+	if MaxRestarts != "" {
+		var err error
+		maxRestarts, err = strconv.Atoi(MaxRestarts)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("config_error"), horus.WithMessage("parsing --max-restarts"))
+	}
 
-	home, err := domovoi.FindHome(verbose)
-	horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("env_error"), horus.WithMessage("getting home directory"))
-	logDir := filepath.Join(home, ".lilith", "logs")
-	horus.CheckErr(
-		domovoi.CreateDir(logDir, verbose),
-		horus.WithOp(op),
-		horus.WithMessage(fmt.Sprintf("creating %q", logDir)),
-		horus.WithCategory("env_error"),
-	)
-	logPath := filepath.Join(logDir, LogName+".log")
+	logMaxFiles := 0
+	if LogMaxFiles != "" {
+		var err error
+		logMaxFiles, err = strconv.Atoi(LogMaxFiles)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("config_error"), horus.WithMessage("parsing --log-max-files"))
+	}
 
-	meta := &DaemonMeta{
-		Name:       DaemonName,
-		Group:      GroupName,
-		WatchDir:   WatchDir,
-		ScriptPath: ScriptPath,
-		LogPath:    logPath,
-		InvokedAt:  time.Now(),
+	env, err := parseEnvFlags(configured.Env, EnvVars)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("parsing --env"))
+
+	meta, err := newDaemonMeta(DaemonName, GroupName, LogName, Workflow{
+		Watch:         WatchPaths,
+		Script:        ScriptPath,
+		Run:           RunBody,
+		Interpreter:   Interpreter,
+		Args:          ScriptArgs,
+		Cwd:           WorkDir,
+		Env:           env,
+		EnvFile:       EnvFile,
+		ChangedFile:   ChangedFile,
+		LogFormat:     LogFormat,
+		LogMaxSize:    LogMaxSize,
+		LogMaxFiles:   logMaxFiles,
+		LogMaxAge:     LogMaxAge,
+		LogCompress:   LogCompress,
+		KeepLogs:      KeepLogs,
+		Backend:       Backend,
+		Debounce:      Debounce,
+		Ignore:        Ignore,
+		Extensions:    Extensions,
+		Events:        Events,
+		StopTimeout:   StopTimeout,
+		Restart:       Restart,
+		MaxRestarts:   maxRestarts,
+		RestartWindow: RestartWindow,
+		DependsOn:     configured.DependsOn,
+		OnSuccess:     configured.OnSuccess,
+		OnFailure:     configured.OnFailure,
+		AllowOverlap:  AllowOverlap,
+	})
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("validating invocation"))
+
+	meta.Workflow = ConfigName
+	if SaveFile != "" {
+		meta.Workflow = DaemonName
 	}
 
 	for _, path := range mustListDaemonMetaFiles() {
 		existing := mustLoadMeta(path)
-		if existing.WatchDir == WatchDir && isDaemonActive(existing) {
+		if existing.Name == meta.Name && isDaemonActive(existing) {
 			horus.CheckErr(
 				fmt.Errorf("daemon already running"),
 				horus.WithMessage(existing.Name),
@@ -241,30 +420,330 @@ func RunInvoke(cmd *cobra.Command, args []string) {
 			)
 		}
 	}
+	for _, o := range watchOverlaps(meta) {
+		if !meta.AllowOverlap {
+			horus.CheckErr(
+				fmt.Errorf("overlapping watch paths"),
+				horus.WithMessage(o.Daemon.Name),
+				horus.WithExitCode(2),
+				horus.WithFormatter(func(he *horus.Herror) string {
+					return "daemon " + chalk.Red.Color(he.Message) + " already watches: " + o.String() + "\n" +
+						"pass --allow-overlap or set allow_overlap to invoke anyway"
+				}),
+			)
+		}
+		warnOverlap(meta, o)
+	}
 
-	pid, err := spawnWatcher(meta)
+	// persist an ad hoc invocation for reuse with --config, once validated & before anything is spawned,
+	// so a workflow that cannot be saved leaves no daemon behind
+	if SaveFile != "" {
+		horus.CheckErr(
+			saveWorkflow(SaveFile, DaemonName, workflowOf(meta)),
+			horus.WithOp(op),
+			horus.WithCategory("config_error"),
+			horus.WithMessage(fmt.Sprintf("saving workflow %q", DaemonName)),
+		)
+		if !structuredOutput() {
+			fmt.Printf("%s saved workflow %q to %s\n", paint(chalk.Green, "OK:"), DaemonName, SaveFile)
+		}
+	}
+
+	pid, err := startDaemon(meta)
 	horus.CheckErr(
 		err,
 		horus.WithOp(op),
 		horus.WithCategory("env_error"),
 		horus.WithMessage("starting watcher"),
 	)
-	meta.PID = pid
 
-	horus.CheckErr(
-		saveMeta(meta),
-		horus.WithOp(op),
-		horus.WithCategory("env_error"),
-		horus.WithMessage("writing metadata"),
-	)
+	report(meta, "invoked", fmt.Sprintf(
+		"invoked daemon %s group %s PID %s",
+		paint(chalk.Green, DaemonName),
+		paint(chalk.Green, GroupName),
+		paint(chalk.Green, strconv.Itoa(pid)),
+	))
+
+	flushReports()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// bulkInvoke reports whether invoke targets whole groups: --all, or --group without any daemon description
+func bulkInvoke(cmd *cobra.Command) bool {
+	if InvokeAll {
+		return true
+	}
+	for _, flag := range []string{"config", "name", "watch", "script", "run", "save"} {
+		if cmd.Flags().Changed(flag) {
+			return false
+		}
+	}
+	return cmd.Flags().Changed("group")
+}
 
-	fmt.Printf(
-		"invoked daemon %s group %s PID %s\n",
-		chalk.Green.Color(DaemonName),
-		chalk.Green.Color(GroupName),
-		chalk.Green.Color(strconv.Itoa(pid)),
+// bulkFlags are the flags that apply to whole groups; any other describes a single daemon
+var bulkFlags = map[string]bool{"group": true, "all": true, "allow-overlap": true, "output": true, "verbose": true}
+
+// refuseBulkFlags rejects daemon flags given along whole groups, which start workflows as configured
+// rather than silently dropping them
+func refuseBulkFlags(cmd *cobra.Command) error {
+	var refused []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !bulkFlags[f.Name] {
+			refused = append(refused, "--"+f.Name)
+		}
+	})
+	if len(refused) == 0 {
+		return nil
+	}
+	return horus.NewCategorizedHerror(
+		"invoke.bulk", "validation",
+		fmt.Sprintf("%s cannot be combined with --group alone or --all; edit the workflows, or pass --config", strings.Join(refused, ", ")),
+		nil, map[string]any{"flags": refused},
 	)
-	// BUG: cannot execute daemons passed on the command line
+}
+
+// invokeWorkflows starts every workflow of a group, or of all groups when empty,
+// reporting each one & failing once all were attempted if any could not start
+func invokeWorkflows(group string) {
+	const op = "lilith.invoke"
+	defer flushReports()
+
+	workflows, err := loadWorkflows(group)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("loading workflows"))
+	if len(workflows) == 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "config_error", "no workflows found", nil, map[string]any{"group": group, "dir": configDir()}))
+	}
+	workflows, err = orderWorkflows(workflows)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("ordering workflows by depends_on"))
+
+	failed := 0
+	unstarted := map[string]bool{} // workflows whose dependents must not start either
+	for _, wf := range workflows {
+		meta, err := workflowMeta(wf)
+		for _, dep := range wf.DependsOn {
+			if err == nil && unstarted[dep] {
+				err = horus.NewCategorizedHerror(op, "spawn_error", fmt.Sprintf("dependency %q failed to start", dep), nil, nil)
+			}
+		}
+		if err == nil {
+			var alive *DaemonMeta
+			if alive, err = activeWatcher(meta); alive != nil && alive.Name == meta.Name {
+				reportAs(paint(chalk.Yellow, "SKIP:"), alive, "skipped: already alive", fmt.Sprintf("daemon %q already alive with PID %d", alive.Name, alive.PID))
+				continue
+			}
+		}
+		if err == nil {
+			_, err = startDaemon(meta)
+		}
+		if err != nil {
+			failed++
+			unstarted[wf.Name] = true
+			if meta == nil {
+				meta = &DaemonMeta{Name: wf.Name, Group: wf.Group, Workflow: wf.Name}
+			}
+			reportAs(paint(chalk.Red, "FAILED:"), meta, "failed: "+err.Error(), fmt.Sprintf("workflow %q: %v", wf.Name, err))
+			continue
+		}
+		report(meta, "invoked", fmt.Sprintf(
+			"invoked daemon %s group %s PID %s",
+			paint(chalk.Green, meta.Name),
+			paint(chalk.Green, meta.Group),
+			paint(chalk.Green, strconv.Itoa(meta.PID)),
+		))
+	}
+
+	if failed > 0 {
+		flushReports()
+		horus.CheckErr(horus.NewCategorizedHerror(
+			op, "spawn_error", fmt.Sprintf("%d of %d workflows failed to start", failed, len(workflows)), nil,
+			map[string]any{"group": group},
+		))
+	}
+}
+
+// activeWatcher returns meta's daemon when it is already alive, for the caller to skip.
+// A live daemon overlapping meta's paths is an error unless meta allows overlap, which is only warned about
+func activeWatcher(meta *DaemonMeta) (*DaemonMeta, error) {
+	if alive := liveNamesake(meta.Name); alive != nil {
+		return alive, nil
+	}
+	return refuseOverlaps(meta)
+}
+
+// liveNamesake returns the live daemon called name, whether invoked from a workflow or ad hoc,
+// as spawning another under that name would overwrite its metadata & orphan its group
+func liveNamesake(name string) *DaemonMeta {
+	existing, err := loadMeta(name)
+	if err != nil || !isDaemonActive(existing) {
+		return nil
+	}
+	return existing
+}
+
+// workflowMeta prepares a daemon named after its workflow, as invoke --config would
+func workflowMeta(wf workflowEntry) (*DaemonMeta, error) {
+	meta, err := newDaemonMeta(wf.Name, wf.Group, wf.Name, wf.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	meta.Workflow = wf.Name
+	return meta, nil
+}
+
+// newDaemonMeta validates a workflow & resolves its paths into the metadata of a daemon yet to be spawned
+func newDaemonMeta(name, group, logName string, wf Workflow) (*DaemonMeta, error) {
+	const op = "daemon.new"
+
+	if wf.Backend == "" {
+		wf.Backend = defaultBackend
+	}
+	watcher, err := lookupWatcher(wf.Backend)
+	if err != nil {
+		return nil, err
+	}
+	if wf.Debounce != "" {
+		if _, err := time.ParseDuration(wf.Debounce); err != nil {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "parsing debounce", err, map[string]any{"daemon": name})
+		}
+	}
+	if v, ok := watcher.(filterValidator); ok {
+		if err := v.validateFilters(wf); err != nil {
+			return nil, err
+		}
+	}
+	if wf.StopTimeout != "" {
+		if _, err := time.ParseDuration(wf.StopTimeout); err != nil {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "parsing stop_timeout", err, map[string]any{"daemon": name})
+		}
+	}
+	if err := validRestartPolicy(wf.Restart); err != nil {
+		return nil, err
+	}
+	if err := validLogFormat(wf.LogFormat); err != nil {
+		return nil, err
+	}
+	if wf.RestartWindow != "" {
+		if _, err := time.ParseDuration(wf.RestartWindow); err != nil {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "parsing restart_window", err, map[string]any{"daemon": name})
+		}
+	}
+
+	if len(wf.Watch) == 0 {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "no watch path", nil, map[string]any{"daemon": name})
+	}
+	// relative watch paths, script & env_file sit in cwd when one is set
+	cwd := wf.Cwd
+	if cwd != "" {
+		if cwd, err = resolvePath(cwd); err != nil {
+			return nil, horus.Wrap(err, op, "resolving cwd")
+		}
+		if info, err := os.Stat(cwd); err != nil || !info.IsDir() {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "cwd is not a directory", err, map[string]any{"cwd": cwd})
+		}
+	}
+	var watch []string
+	for _, p := range wf.Watch {
+		resolved, err := resolveIn(cwd, p)
+		if err != nil {
+			return nil, horus.Wrap(err, op, "resolving watch path")
+		}
+		if !slices.Contains(watch, resolved) {
+			watch = append(watch, resolved)
+		}
+	}
+	if err := validScript(wf); err != nil {
+		return nil, err
+	}
+	script := wf.Script
+	// exec looks bare command names up on PATH, anything else is a file
+	if script != "" && (wf.Interpreter != interpreterExec || strings.ContainsRune(script, filepath.Separator)) {
+		if script, err = resolveIn(cwd, script); err != nil {
+			return nil, horus.Wrap(err, op, "resolving script path")
+		}
+	}
+	if _, err := newWatchFilter(watch, wf.Ignore, wf.Extensions, wf.Events); err != nil {
+		return nil, err
+	}
+
+	envFile := wf.EnvFile
+	if envFile != "" {
+		if envFile, err = resolveIn(cwd, envFile); err != nil {
+			return nil, horus.Wrap(err, op, "resolving env_file")
+		}
+		if _, err := loadEnvFile(envFile); err != nil {
+			return nil, err
+		}
+	}
+
+	logDir := filepath.Join(home, ".lilith", "logs")
+	if err := domovoi.CreateDir(logDir, verbose); err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "creating log directory", err, map[string]any{"dir": logDir})
+	}
+
+	meta := &DaemonMeta{
+		Name:        name,
+		Group:       group,
+		WatchPaths:  watch,
+		ScriptPath:  script,
+		Run:         wf.Run,
+		Interpreter: wf.Interpreter,
+		Args:        wf.Args,
+		Cwd:         cwd,
+		Env:         wf.Env,
+		EnvFile:     envFile,
+		Environ:     os.Environ(),
+		ChangedFile: wf.ChangedFile,
+		Backend:     wf.Backend,
+		Debounce:    wf.Debounce,
+		Ignore:      wf.Ignore,
+		Extensions:  wf.Extensions,
+		Events:      wf.Events,
+		StopTimeout: wf.StopTimeout,
+
+		Restart:       wf.Restart,
+		MaxRestarts:   wf.MaxRestarts,
+		RestartWindow: wf.RestartWindow,
+		DependsOn:     wf.DependsOn,
+		OnSuccess:     wf.OnSuccess,
+		OnFailure:     wf.OnFailure,
+		AllowOverlap:  wf.AllowOverlap,
+		LogPath:       filepath.Join(logDir, logName+".log"),
+		LogFormat:     wf.LogFormat,
+		LogMaxSize:    wf.LogMaxSize,
+		LogMaxFiles:   wf.LogMaxFiles,
+		LogMaxAge:     wf.LogMaxAge,
+		LogCompress:   wf.LogCompress,
+		KeepLogs:      wf.KeepLogs,
+		InvokedAt:     time.Now(),
+	}
+	// the log keys of ~/.lilith/lilith.toml are checked along those of the workflow
+	if _, err := logRotationOf(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// startDaemon persists meta & spawns its watcher, leaving no metadata behind when spawning fails
+func startDaemon(meta *DaemonMeta) (int, error) {
+	const op = "daemon.start"
+
+	// metadata goes first: the watcher's rite loads it on its initial run
+	if err := saveMeta(meta); err != nil {
+		return 0, horus.Wrap(err, op, "writing metadata")
+	}
+
+	pid, err := spawnWatcher(meta)
+	if err != nil {
+		_ = os.Remove(filepath.Join(GetDaemonDir(), meta.Name+".json"))
+		return 0, err
+	}
+
+	if err := saveMeta(meta); err != nil {
+		return 0, horus.Wrap(err, op, "writing metadata")
+	}
+	return pid, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
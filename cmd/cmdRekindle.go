@@ -19,8 +19,9 @@ package cmd
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 import (
+	"errors"
 	"fmt"
-	"path/filepath"
+	"os"
 	"time"
 
 	"github.com/DanielRivasMD/horus"
@@ -54,8 +55,9 @@ var (
 func init() {
 	rootCmd.AddCommand(rekindleCmd)
 
-	rekindleCmd.Flags().BoolVar(&rekindleAll, "all", false, "Rekindle all dead daemons")
-	rekindleCmd.Flags().StringVar(&rekindleGroup, "group", "", "Rekindle all daemons in a specific group")
+	rekindleCmd.Flags().BoolVar(&rekindleAll, "all", false, "Rekindle all daemons, restarting those still alive")
+	rekindleCmd.Flags().StringVar(&rekindleGroup, "group", "", "Rekindle all daemons in a specific group, restarting those still alive")
+	addOutputFlag(rekindleCmd)
 
 	horus.CheckErr(rekindleCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups), horus.WithOp("rekindle.init"), horus.WithMessage("registering config completion"))
 }
@@ -65,7 +67,8 @@ func init() {
 var helpRekindle = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
-	"Restart daemons in limbo using persisted metadata",
+	"Restart daemons in limbo using persisted metadata\n"+
+		"A watcher still running, paused or not, is stopped first, so live daemons are restarted",
 )
 
 var exampleRekindle = formatExample(
@@ -83,50 +86,77 @@ func runRekindle(cmd *cobra.Command, args []string) {
 	switch {
 	case rekindleAll:
 		rekindleAllDaemons()
-		return
 
 	case rekindleGroup != "":
 		rekindleGroupDaemons(rekindleGroup)
-		return
 
 	case len(args) == 1:
 		name := args[0]
-		meta := mustLoadMeta(filepath.Join(GetDaemonDir(), name))
-		pid := mustSpawnWatcher(*meta)
-		meta.PID = pid
-		meta.InvokedAt = time.Now()
-		horus.CheckErr(saveMeta(meta), horus.WithOp(op), horus.WithMessage("updating metadata"))
-		fmt.Printf("%s rekindled %q with PID %d\n", chalk.Green.Color("OK:"), name, pid)
-		return
+		meta, err := loadMeta(name)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading metadata for %q", name)))
+		rekindleDaemon(meta)
 
 	default:
 		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "missing daemon name or flag", nil, nil))
 	}
+	finishReports(op)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// rekindleDaemon respawns the watcher from metadata & reports it, failure included
+func rekindleDaemon(meta *DaemonMeta) {
+	pid, err := reviveDaemon(meta)
+	if err != nil {
+		reportFailure(meta, err, fmt.Sprintf("rekindling %q", meta.Name))
+		return
+	}
+	report(meta, "rekindled", fmt.Sprintf("rekindled %q with PID %d", meta.Name, pid))
+}
+
+// reviveDaemon respawns the watcher & persists the new PID.
+// A previous watcher still holding on, frozen or not, is stopped first so it is not orphaned
+func reviveDaemon(meta *DaemonMeta) (int, error) {
+	const op = "daemon.revive"
+
+	if _, err := stopDaemon(meta, stopTimeoutOf(meta)); err != nil {
+		return 0, horus.Wrap(err, op, fmt.Sprintf("retiring previous PID %d", meta.PID))
+	}
+
+	pid, err := spawnWatcher(meta)
+	if err != nil {
+		return 0, err
+	}
+	meta.InvokedAt = time.Now()
+	meta.Frozen = false
+	meta.FrozenAt = time.Time{}
+	meta.Stopping = false
+
+	// a daemon slain while respawning must stay slain, rather than its metadata being written back
+	_, err = updateMeta(meta.Name, func(current *DaemonMeta) bool {
+		*current = *meta
+		return true
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		_, _ = stopGroup(meta, stopTimeoutOf(meta))
+		return 0, horus.NewCategorizedHerror(op, "runtime_error", "daemon slain while respawning", err, map[string]any{"daemon": meta.Name})
+	}
+	if err != nil {
+		return pid, horus.Wrap(err, op, "updating metadata")
+	}
+	return pid, nil
+}
+
+// rekindleAllDaemons & rekindleGroupDaemons respawn dependencies first
 func rekindleAllDaemons() {
-	for _, path := range mustListDaemonMetaFiles() {
-		meta := mustLoadMeta(path)
-		pid := mustSpawnWatcher(*meta)
-		meta.PID = pid
-		meta.InvokedAt = time.Now()
-		horus.CheckErr(saveMeta(meta))
-		fmt.Printf("%s rekindled %q with PID %d\n", chalk.Green.Color("OK:"), meta.Name, pid)
+	for _, meta := range mustOrderedDaemons("") {
+		rekindleDaemon(meta)
 	}
 }
 
 func rekindleGroupDaemons(group string) {
-	for _, path := range mustListDaemonMetaFiles() {
-		if matchesGroup(path, group) {
-			meta := mustLoadMeta(path)
-			pid := mustSpawnWatcher(*meta)
-			meta.PID = pid
-			meta.InvokedAt = time.Now()
-			horus.CheckErr(saveMeta(meta))
-			fmt.Printf("%s rekindled %q with PID %d\n", chalk.Green.Color("OK:"), meta.Name, pid)
-		}
+	for _, meta := range mustOrderedDaemons(group) {
+		rekindleDaemon(meta)
 	}
 }
 
@@ -0,0 +1,486 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/domovoi"
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var riteCmd = &cobra.Command{
+	Use:    "rite",
+	Hidden: true,
+	Short:  "Execute a single run of a daemon's script",
+	Long:   helpRite,
+
+	Run: runRite,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	riteName     string
+	riteUpstream string
+	riteDepth    int
+	riteEmulate  bool
+	riteCreated  bool
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(riteCmd)
+
+	riteCmd.Flags().StringVar(&riteName, "name", "", "Daemon whose script is executed")
+	riteCmd.Flags().StringVar(&riteUpstream, "upstream", "", "Run that chained this one, as <daemon>@<run>")
+	riteCmd.Flags().IntVar(&riteDepth, "depth", 0, "Chained runs leading to this one")
+	riteCmd.Flags().BoolVar(&riteEmulate, "emulate", false, "Apply debounce & events here, for backends without them")
+	riteCmd.Flags().BoolVar(&riteCreated, "created", false, "The changed paths were created, with --emulate")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpRite = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Executed by every watcher backend on change, with the changed paths as arguments\n"+
+		"Runs the daemon's script once, capturing its output & appending the run to the ledger\n"+
+		"Then triggers the on_success or on_failure workflows of the daemon",
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// maxRunOutputs bounds how many captured run outputs are kept per daemon
+const maxRunOutputs = 50
+
+// maxChainDepth bounds on_success & on_failure chains, so workflows triggering each other stop
+const maxChainDepth = 8
+
+// RunRecord is one line of ~/.lilith/daemon/<name>.runs.jsonl
+type RunRecord struct {
+	ID         string    `json:"id"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Duration   string    `json:"duration"`
+	Trigger    []string  `json:"trigger,omitempty"`
+	Upstream   string    `json:"upstream,omitempty"` // <daemon>@<run> that chained this run
+	Depth      int       `json:"depth,omitempty"`
+	ExitCode   int       `json:"exitCode"`
+	Signal     string    `json:"signal,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the script exited zero
+func (r *RunRecord) Succeeded() bool {
+	return r.ExitCode == 0 && r.Signal == "" && r.Error == ""
+}
+
+// Summary renders how the run ended, e.g. "exit 1" or "signal terminated"
+func (r *RunRecord) Summary() string {
+	switch {
+	case r.Error != "":
+		return "failed to start"
+	case r.Signal != "":
+		return "signal " + r.Signal
+	default:
+		return fmt.Sprintf("exit %d", r.ExitCode)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runRite(cmd *cobra.Command, args []string) {
+	const op = "lilith.rite"
+
+	horus.CheckEmpty(riteName, "`--name` is required", horus.WithOp(op), horus.WithCategory("spawn_error"))
+
+	meta, err := loadMeta(riteName)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading metadata for %q", riteName)))
+
+	trigger := trimTrigger(args)
+	if len(trigger) == 0 {
+		trigger = watchexecTrigger()
+	}
+	if riteEmulate && !admitChange(meta, trigger, riteCreated) {
+		return
+	}
+
+	// stdout is the shim relaying into the daemon log, or the log itself for chained runs
+	out, err := logOutput(meta)
+	log := newLogWriter(out, meta.LogFormat)
+	if err != nil {
+		fmt.Fprintln(log.stream(streamErr, ""), err)
+	}
+	rec := &RunRecord{Trigger: trigger, Upstream: riteUpstream, Depth: riteDepth}
+	performRite(meta, rec, log)
+	chainRites(meta, rec, log.stream(streamErr, rec.ID))
+	os.Exit(rec.ExitCode)
+}
+
+// performRite executes the daemon's script once, framing its timestamped output in the daemon log
+// between a header & a footer, teeing it to a per-run capture, then appends the run to the ledger
+func performRite(meta *DaemonMeta, rec *RunRecord, log *logWriter) {
+	const op = "daemon.rite"
+
+	rec.StartedAt = time.Now()
+	// runs of one daemon may start within the same millisecond, from the watcher & a chain, but never in one process
+	rec.ID = rec.StartedAt.Format("060102-150405.000") + "-" + strconv.Itoa(os.Getpid())
+
+	logOut, logErr := log.stream(streamOut, rec.ID), log.stream(streamErr, rec.ID)
+	if err := log.runStarted(rec); err != nil {
+		fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "writing run header"))
+	}
+
+	stdout, stderr := io.Writer(logOut), io.Writer(logErr)
+	if capture, err := openRunOutput(meta.Name, rec.ID); err != nil {
+		fmt.Fprintln(logErr, horus.Wrap(err, op, "capturing run output"))
+	} else {
+		defer capture.Close()
+		stdout, stderr = io.MultiWriter(logOut, capture), io.MultiWriter(logErr, capture)
+	}
+
+	c := scriptCommand(meta)
+	env, cleanup, err := runEnv(meta, rec)
+	if err != nil {
+		fmt.Fprintln(logErr, horus.Wrap(err, op, "preparing run environment"))
+	}
+	defer cleanup()
+	c.Env = append(os.Environ(), env...)
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	// group signals reach the script directly; the rite waits to record how it ended
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	if err := c.Start(); err != nil {
+		rec.Error = err.Error()
+		rec.ExitCode = 127
+		fmt.Fprintf(logErr, "lilith: %s: %v\n", scriptLabel(meta), err)
+	} else {
+		go func() {
+			for sig := range sigs {
+				_ = c.Process.Signal(sig)
+			}
+		}()
+		_ = c.Wait()
+		if status, ok := c.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			rec.Signal = status.Signal().String()
+			rec.ExitCode = 128 + int(status.Signal())
+		} else {
+			rec.ExitCode = c.ProcessState.ExitCode()
+		}
+	}
+
+	_ = logOut.Flush()
+	_ = logErr.Flush()
+
+	rec.FinishedAt = time.Now()
+	rec.Duration = rec.FinishedAt.Sub(rec.StartedAt).Round(time.Millisecond).String()
+	if err := log.runEnded(rec); err != nil {
+		fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "writing run footer"))
+	}
+	if err := appendJSONLine(ledgerPath(meta.Name), rec); err != nil {
+		fmt.Fprintln(logErr, horus.Wrap(err, op, "recording run"))
+	}
+	pruneRunOutputs(meta.Name)
+}
+
+// chainRites starts one detached run of every workflow named by on_success or on_failure,
+// each writing to the log of its own daemon; what happens is reported to diag
+func chainRites(meta *DaemonMeta, rec *RunRecord, diag io.Writer) {
+	const op = "daemon.chain"
+
+	targets, hook := meta.OnSuccess, "on_success"
+	if !rec.Succeeded() {
+		targets, hook = meta.OnFailure, "on_failure"
+	}
+	if len(targets) == 0 {
+		return
+	}
+	if rec.Depth+1 > maxChainDepth {
+		fmt.Fprintf(diag, "lilith: %s: chain depth %d reached, not triggering %s\n", hook, maxChainDepth, strings.Join(targets, ", "))
+		return
+	}
+
+	for _, target := range targets {
+		downstream := findDaemonByWorkflow(target)
+		switch {
+		case downstream == nil:
+			fmt.Fprintf(diag, "lilith: %s: workflow %q has no daemon, skipped\n", hook, target)
+			continue
+		case downstream.Frozen:
+			fmt.Fprintf(diag, "lilith: %s: daemon %q is frozen, skipped\n", hook, downstream.Name)
+			continue
+		case downstream.PGID <= 0 || !isDaemonActive(downstream):
+			fmt.Fprintf(diag, "lilith: %s: daemon %q is not running, skipped\n", hook, downstream.Name)
+			continue
+		}
+
+		if err := spawnChainedRite(downstream, meta.Name+"@"+rec.ID, rec.Depth+1); err != nil {
+			fmt.Fprintln(diag, horus.Wrap(err, op, fmt.Sprintf("triggering %q", downstream.Name)))
+			continue
+		}
+		fmt.Fprintf(diag, "lilith: %s: triggered %q\n", hook, downstream.Name)
+	}
+}
+
+// spawnChainedRite runs a rite for meta inside its process group, so it outlives the upstream watcher
+// & is frozen, thawed or slain along with the daemon it belongs to
+func spawnChainedRite(meta *DaemonMeta, upstream string, depth int) error {
+	const op = "daemon.spawnChained"
+
+	c, err := selfCommand("rite", "--name", meta.Name, "--upstream", upstream, "--depth", strconv.Itoa(depth))
+	if err != nil {
+		return err
+	}
+	if err := applyExecContext(c, meta); err != nil {
+		return horus.Wrap(err, op, "preparing execution context")
+	}
+	f, err := os.OpenFile(meta.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "opening log", err, map[string]any{"path": meta.LogPath})
+	}
+	defer f.Close()
+
+	c.Stdout = f
+	c.Stderr = f
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: meta.PGID}
+	if err := c.Start(); err != nil {
+		return horus.NewCategorizedHerror(op, "spawn_error", "starting chained rite", err, map[string]any{"daemon": meta.Name, "pgid": meta.PGID})
+	}
+	return c.Process.Release()
+}
+
+// findDaemonByWorkflow returns the first daemon invoked from a workflow, or named after it
+func findDaemonByWorkflow(name string) *DaemonMeta {
+	for _, path := range mustListDaemonMetaFiles() {
+		meta, err := loadMeta(nameFrom(path))
+		if err == nil && daemonKey(meta) == name {
+			return meta
+		}
+	}
+	return nil
+}
+
+// riteCommand builds the lilith invocation a watcher runs on change
+func riteCommand(name string, trigger []string) (*exec.Cmd, error) {
+	return selfCommand(append([]string{"rite", "--name", name, "--"}, trigger...)...)
+}
+
+// watchexecTrigger recovers changed paths from the environment watchexec sets for its command
+// when run with --emit-events-to environment
+func watchexecTrigger() []string {
+	common := os.Getenv("WATCHEXEC_COMMON_PATH")
+	var out []string
+	for _, kind := range []string{"WRITTEN", "CREATED", "REMOVED", "RENAMED", "META_CHANGED", "OTHERWISE_CHANGED"} {
+		for _, p := range filepath.SplitList(os.Getenv("WATCHEXEC_" + kind + "_PATH")) {
+			if p != "" {
+				out = append(out, filepath.Join(common, p))
+			}
+		}
+	}
+	return out
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ledgerPath returns ~/.lilith/daemon/<name>.runs.jsonl
+func ledgerPath(name string) string {
+	return filepath.Join(GetDaemonDir(), name+".runs.jsonl")
+}
+
+// runOutputDir returns ~/.lilith/daemon/<name>.runs, holding one captured output per run
+func runOutputDir(name string) string {
+	return filepath.Join(GetDaemonDir(), name+".runs")
+}
+
+// runOutputPath returns the capture of a single run
+func runOutputPath(name, id string) string {
+	return filepath.Join(runOutputDir(name), id+".log")
+}
+
+// loadRuns reads the ledger of a daemon, oldest first
+func loadRuns(name string) ([]RunRecord, error) {
+	return readJSONLines[RunRecord](ledgerPath(name))
+}
+
+func openRunOutput(name, id string) (*os.File, error) {
+	const op = "daemon.openRunOutput"
+
+	if err := domovoi.CreateDir(runOutputDir(name), false); err != nil {
+		return nil, horus.Wrap(err, op, "creating run output directory")
+	}
+	path := runOutputPath(name, id)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "creating run output", err, map[string]any{"path": path})
+	}
+	return f, nil
+}
+
+// pruneRunOutputs drops the oldest captures beyond maxRunOutputs; the ledger itself is kept whole
+func pruneRunOutputs(name string) {
+	files, err := filepath.Glob(filepath.Join(runOutputDir(name), "*.log"))
+	if err != nil || len(files) <= maxRunOutputs {
+		return
+	}
+	// IDs start with timestamps, so lexical order is chronological
+	sort.Strings(files)
+	for _, f := range files[:len(files)-maxRunOutputs] {
+		_ = os.Remove(f)
+	}
+}
+
+// describeCause summarizes what started a run for a table cell: the upstream run or the trigger paths
+func describeCause(r *RunRecord) string {
+	if r.Upstream != "" {
+		return "← " + r.Upstream
+	}
+	return describeTrigger(r.Trigger)
+}
+
+// describeTrigger summarizes trigger paths for a table cell
+func describeTrigger(trigger []string) string {
+	switch len(trigger) {
+	case 0:
+		return "-"
+	case 1:
+		return trigger[0]
+	default:
+		return fmt.Sprintf("%s (+%d)", trigger[0], len(trigger)-1)
+	}
+}
+
+// admitChange applies events & debounce for backends reporting changes without either, as entr does.
+// The kind of change is told from whether a path still exists, unless the caller saw it created;
+// the run then waits until the changed paths have been quiet for the debounce period.
+// entr queues another run for changes made meanwhile, which is dropped when no path changed since
+// the last admitted run; removed paths leave no time to compare, so their runs always proceed
+func admitChange(meta *DaemonMeta, trigger []string, created bool) bool {
+	if len(meta.Events) > 0 {
+		admitted := false
+		for _, p := range trigger {
+			for _, kind := range changeKinds(p, created) {
+				admitted = admitted || slices.Contains(meta.Events, kind)
+			}
+		}
+		if !admitted {
+			return false
+		}
+	}
+
+	d, err := time.ParseDuration(meta.Debounce)
+	if err != nil || d <= 0 {
+		return true
+	}
+	settled := settledPath(meta.Name)
+	if last := lastModified(trigger); !last.IsZero() {
+		if info, err := os.Stat(settled); err == nil && !last.After(info.ModTime()) {
+			return false
+		}
+	}
+	for {
+		last := lastModified(trigger)
+		if last.IsZero() {
+			// removed paths change no further
+			time.Sleep(d)
+			break
+		}
+		quiet := d - time.Since(last)
+		if quiet <= 0 {
+			break
+		}
+		time.Sleep(min(quiet, d))
+	}
+	markSettled(settled)
+	return true
+}
+
+// settledPath marks when the last emulated debounce admitted a run of a daemon
+func settledPath(name string) string {
+	return filepath.Join(runOutputDir(name), "settled")
+}
+
+// markSettled stamps the marker with the current time; a run missing it merely runs again
+func markSettled(path string) {
+	if err := domovoi.CreateDir(filepath.Dir(path), false); err != nil {
+		return
+	}
+	now := time.Now()
+	if err := os.WriteFile(path, nil, 0600); err == nil {
+		_ = os.Chtimes(path, now, now)
+	}
+}
+
+// changeKinds are the kinds of change p may have undergone; a vanished path was removed or renamed away
+func changeKinds(p string, created bool) []string {
+	if created {
+		return []string{eventCreate}
+	}
+	if _, err := os.Lstat(p); os.IsNotExist(err) {
+		return []string{eventRemove, eventRename}
+	}
+	return []string{eventModify}
+}
+
+// lastModified is the latest modification among paths, zero when none exists
+func lastModified(paths []string) time.Time {
+	var last time.Time
+	for _, p := range paths {
+		if info, err := os.Lstat(p); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last
+}
+
+// trimTrigger removes duplicates while keeping first-seen order
+func trimTrigger(paths []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
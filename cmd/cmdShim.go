@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var shimCmd = &cobra.Command{
+	Use:    "shim",
+	Hidden: true,
+	Short:  "Supervise a watcher & record how it exits",
+	Long:   helpShim,
+
+	Args: cobra.MinimumNArgs(1),
+
+	Run: runShim,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	shimName string
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(shimCmd)
+
+	shimCmd.Flags().StringVar(&shimName, "name", "", "Daemon whose history is appended")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpShim = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Process spawned by invoke & rekindle between lilith & the watcher backend\n"+
+		"Timestamps the watcher output into log records, relaying those its rites already wrote & rotating the log\n"+
+		"Waits on the watcher & appends its exit code, signal, start & stop times to the daemon history",
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ExitRecord is one line of ~/.lilith/daemon/<name>.history.jsonl
+type ExitRecord struct {
+	ShimPID   int       `json:"shimPid"`
+	PID       int       `json:"pid"`
+	Command   []string  `json:"command"`
+	StartedAt time.Time `json:"startedAt"`
+	StoppedAt time.Time `json:"stoppedAt"`
+	Duration  string    `json:"duration"`
+	ExitCode  int       `json:"exitCode"`
+	Signal    string    `json:"signal,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Failed reports whether the watcher ended any other way than a zero exit
+func (r *ExitRecord) Failed() bool {
+	return r.ExitCode != 0 || r.Signal != "" || r.Error != ""
+}
+
+// Summary renders the exit for humans, e.g. "exit 1" or "signal killed"
+func (r *ExitRecord) Summary() string {
+	switch {
+	case r.Error != "":
+		return "failed to start"
+	case r.Signal != "":
+		return "signal " + r.Signal
+	default:
+		return fmt.Sprintf("exit %d", r.ExitCode)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runShim(cmd *cobra.Command, args []string) {
+	const op = "lilith.shim"
+
+	horus.CheckEmpty(shimName, "`--name` is required", horus.WithOp(op), horus.WithCategory("spawn_error"))
+
+	rec := ExitRecord{ShimPID: os.Getpid(), Command: args, StartedAt: time.Now()}
+
+	// the watcher shares the shim's process group, so group signals reach both;
+	// the shim outlives them to record the exit & relays anything addressed to it alone
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+
+	// without metadata the watcher still runs, its output going unrotated into the log as text
+	meta, err := loadMeta(shimName)
+	if err != nil {
+		meta = &DaemonMeta{Name: shimName}
+	}
+	out, outErr := logOutput(meta)
+	log := newLogWriter(out, meta.LogFormat)
+	log.relay = true
+	stdout, stderr := log.stream(streamOut, ""), log.stream(streamErr, "")
+	if outErr != nil {
+		fmt.Fprintln(stderr, outErr)
+	}
+
+	child := exec.Command(args[0], args[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = stdout
+	child.Stderr = stderr
+	// leftovers of a run still holding the output open must not keep the exit unrecorded
+	child.WaitDelay = time.Second
+
+	if err := child.Start(); err != nil {
+		rec.Error = err.Error()
+		rec.ExitCode = 127
+		finishShim(&rec, stderr)
+	}
+	rec.PID = child.Process.Pid
+
+	go func() {
+		for sig := range sigs {
+			_ = child.Process.Signal(sig)
+		}
+	}()
+
+	err = child.Wait()
+	if status, ok := child.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		rec.Signal = status.Signal().String()
+		rec.ExitCode = 128 + int(status.Signal())
+	} else {
+		rec.ExitCode = child.ProcessState.ExitCode()
+	}
+	if err != nil && rec.ExitCode == 0 && !errors.Is(err, exec.ErrWaitDelay) {
+		rec.Error = err.Error()
+	}
+	_ = stdout.Flush()
+	finishShim(&rec, stderr)
+}
+
+// finishShim appends the record, reports the exit to the log & exits with the watcher's code
+func finishShim(rec *ExitRecord, diag *streamWriter) {
+	rec.StoppedAt = time.Now()
+	rec.Duration = rec.StoppedAt.Sub(rec.StartedAt).Round(time.Millisecond).String()
+	_ = diag.Flush()
+	if err := appendHistory(shimName, rec); err != nil {
+		fmt.Fprintln(diag, err)
+	}
+	fmt.Fprintf(diag, "lilith: watcher %s after %s\n", rec.Summary(), rec.Duration)
+	os.Exit(rec.ExitCode)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// historyPath returns ~/.lilith/daemon/<name>.history.jsonl
+func historyPath(name string) string {
+	return filepath.Join(GetDaemonDir(), name+".history.jsonl")
+}
+
+// appendHistory writes rec as a single JSON line
+func appendHistory(name string, rec *ExitRecord) error {
+	return appendJSONLine(historyPath(name), rec)
+}
+
+// loadHistory reads every exit record of a daemon, oldest first; a missing file is an empty history
+func loadHistory(name string) ([]ExitRecord, error) {
+	return readJSONLines[ExitRecord](historyPath(name))
+}
+
+// lastExit returns the exit record of the daemon's current shim, if it has finished
+func lastExit(meta *DaemonMeta) *ExitRecord {
+	history, err := loadHistory(meta.Name)
+	if err != nil || len(history) == 0 {
+		return nil
+	}
+	last := history[len(history)-1]
+	if last.ShimPID != meta.PID {
+		return nil
+	}
+	return &last
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
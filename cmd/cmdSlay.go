@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/DanielRivasMD/domovoi"
 	"github.com/DanielRivasMD/horus"
@@ -49,8 +50,10 @@ var slayCmd = &cobra.Command{
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var (
-	slayAll   bool
-	slayGroup string
+	slayAll      bool
+	slayGroup    string
+	slayTimeout  time.Duration
+	slayKeepLogs *bool // nil leaves the choice to keep_logs
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -60,6 +63,9 @@ func init() {
 
 	slayCmd.Flags().BoolVar(&slayAll, "all", false, "Slay all daemons")
 	slayCmd.Flags().StringVar(&slayGroup, "group", "", "Slay all daemons in a specific group")
+	slayCmd.Flags().DurationVar(&slayTimeout, "timeout", 0, "Grace period before SIGKILL (overrides the workflow stop_timeout)")
+	slayCmd.Flags().Bool("keep-logs", false, "Archive log & final metadata in ~/.lilith/archive instead of deleting them (overrides keep_logs)")
+	addOutputFlag(slayCmd)
 
 	horus.CheckErr(
 		slayCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups),
@@ -73,7 +79,9 @@ func init() {
 var helpSlay = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
-	"Gracefully stop alive daemons, removing their metadata and logs to allow clean reinvocation",
+	"Gracefully stop alive daemons, removing their metadata and logs to allow clean reinvocation\n"+
+		"With --keep-logs or keep_logs set, they are moved into ~/.lilith/archive instead, see archive\n"+
+		"Daemons still alive after their stop_timeout are killed with SIGKILL",
 )
 
 var exampleSlay = formatExample(
@@ -81,6 +89,8 @@ var exampleSlay = formatExample(
 	[]string{"slay", "helix"},
 	[]string{"slay", "--group", "<forge>"},
 	[]string{"slay", "--all"},
+	[]string{"slay", "helix", "--timeout", "30s"},
+	[]string{"slay", "helix", "--keep-logs"},
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -88,13 +98,21 @@ var exampleSlay = formatExample(
 func runSlay(cmd *cobra.Command, args []string) {
 	const op = "lilith.slay"
 
+	if cmd.Flags().Changed("keep-logs") {
+		keep, err := cmd.Flags().GetBool("keep-logs")
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading --keep-logs"))
+		slayKeepLogs = &keep
+	}
+
 	switch {
 	case slayAll:
 		slayAllDaemons()
 	case slayGroup != "":
 		slayGroupDaemons(slayGroup)
 	case len(args) == 1:
-		slaySingleDaemon(args[0])
+		meta, err := loadMeta(args[0])
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading metadata for %q", args[0])))
+		slayDaemon(meta)
 	default:
 		// TODO: refactor error message as one liner
 		horus.CheckErr(
@@ -107,63 +125,183 @@ func runSlay(cmd *cobra.Command, args []string) {
 			),
 		)
 	}
+	finishReports(op)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
-func slaySingleDaemon(name string) {
-	const op = "lilith.slay"
-
-	// 1) Load metadata
-	meta, err := loadMeta(name)
-	horus.CheckErr(
-		err,
-		horus.WithOp(op),
-		horus.WithMessage(fmt.Sprintf("loading metadata for %q", name)),
-	)
+// slayDaemon stops a daemon & disposes of its files, reporting a failure rather than exiting,
+// so the rest of a group is still slain
+func slayDaemon(meta *DaemonMeta) {
+	name := meta.Name
 
-	// 2) Try terminating the process, but proceed if it’s already gone
-	horus.CheckErr(
-		terminate(meta.PID),
-		horus.WithOp(op),
-		horus.WithMessage(fmt.Sprintf("terminating PID %d", meta.PID)),
-	)
+	// 1) Stop the process group, escalating if needed, and confirm it is gone
+	timeout := slayTimeout
+	if timeout <= 0 {
+		timeout = stopTimeoutOf(meta)
+	}
+	outcome, err := stopDaemon(meta, timeout)
+	if err != nil {
+		reportFailure(meta, err, fmt.Sprintf("stopping %q (PID %d)", name, meta.PID))
+		return
+	}
 
-	// 3) Remove the metadata JSON file
-	metaFile := filepath.Join(GetDaemonDir(), name+".json")
-	horus.CheckErr(
-		func() error {
-			_, err := domovoi.RemoveFile(metaFile, verbose)(metaFile)
-			return err
-		}(),
-		horus.WithOp(op),
-		horus.WithMessage("removing metadata file"),
-	)
+	// 2) Archive or remove metadata, exit history, run ledger & log
+	keep := keepLogsOf(meta)
+	if slayKeepLogs != nil {
+		keep = *slayKeepLogs
+	}
+	archived := ""
+	if keep {
+		if archived, err = archiveDaemonFiles(meta); err != nil {
+			reportFailure(meta, err, fmt.Sprintf("archiving %q", name))
+			return
+		}
+	}
+	if err := removeDaemonFiles(meta); err != nil {
+		reportFailure(meta, err, fmt.Sprintf("cleaning up %q", name))
+		return
+	}
 
-	// 4) Remove the log file
-	horus.CheckErr(
-		func() error {
-			_, err := domovoi.RemoveFile(meta.LogPath, verbose)(meta.LogPath)
-			return err
-		}(),
-		horus.WithOp(op),
-		horus.WithMessage("removing log file"),
-	)
+	// 3) Final confirmation
+	if archived != "" {
+		report(meta, "slayed: "+outcome, fmt.Sprintf("slayed daemon %q (%s), archived in %s", name, outcome, archived))
+		return
+	}
+	report(meta, "slayed: "+outcome, fmt.Sprintf("slayed daemon %q (%s)", name, outcome))
+}
 
-	// 5) Final confirmation
-	fmt.Printf("%s slayed daemon %q\n", chalk.Green.Color("OK:"), name)
+// removeDaemonFiles deletes everything lilith keeps about a stopped daemon
+func removeDaemonFiles(meta *DaemonMeta) error {
+	const op = "daemon.remove"
+
+	for _, file := range append([]string{
+		filepath.Join(GetDaemonDir(), meta.Name+".json"),
+		historyPath(meta.Name),
+		ledgerPath(meta.Name),
+		meta.LogPath,
+	}, logSegments(meta.LogPath)...) {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := domovoi.RemoveFile(file, verbose)(file); err != nil {
+			return horus.Wrap(err, op, fmt.Sprintf("removing %s", file))
+		}
+	}
+	if err := os.RemoveAll(runOutputDir(meta.Name)); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "removing run outputs", err, map[string]any{"daemon": meta.Name})
+	}
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// terminate sends SIGTERM to pid. Returns nil if the process is already gone.
-func terminate(pid int) error {
-	proc, err := os.FindProcess(pid)
+// outcomes reported by stopDaemon
+const (
+	stopClean  = "clean exit"
+	stopForced = "forced kill"
+	stopDead   = "already dead"
+)
+
+const (
+	// defaultStopTimeout applies when a workflow sets no stop_timeout
+	defaultStopTimeout = 10 * time.Second
+	// killGrace is how long SIGKILL is given to take effect
+	killGrace = 2 * time.Second
+)
+
+// stopTimeoutOf parses the recorded stop_timeout, falling back to defaultStopTimeout
+func stopTimeoutOf(meta *DaemonMeta) time.Duration {
+	if d, err := time.ParseDuration(meta.StopTimeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultStopTimeout
+}
+
+// stopDaemon sends SIGTERM to the daemon's group, waits up to timeout, then escalates to SIGKILL.
+// It only reports success once neither the process nor its group remain.
+// The stop is recorded first so that vigil does not mistake it for a crash, on a fresh copy of the
+// metadata whose PID is the one signalled, as vigil or rekindle may have respawned the daemon since meta was loaded
+func stopDaemon(meta *DaemonMeta, timeout time.Duration) (string, error) {
+	alive := false
+	fresh, err := updateMeta(meta.Name, func(current *DaemonMeta) bool {
+		alive = groupAlive(current)
+		current.Stopping = alive
+		return alive
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		// slain meanwhile by another lilith
+		return stopDead, nil
+	}
 	if err != nil {
-		return fmt.Errorf("find process %d: %w", pid, err)
+		return "", err
+	}
+	*meta = *fresh
+	if !alive {
+		return stopDead, nil
 	}
 
-	if err := proc.Signal(syscall.SIGTERM); err != nil {
+	outcome, err := stopGroup(meta, timeout)
+	if err != nil {
+		// the daemon lives on, so vigil keeps watching over it
+		meta.Stopping = false
+		_, _ = updateMeta(meta.Name, func(current *DaemonMeta) bool {
+			current.Stopping = false
+			return true
+		})
+	}
+	return outcome, err
+}
+
+// stopGroup signals & waits for the daemon's group, escalating from SIGTERM to SIGKILL
+func stopGroup(meta *DaemonMeta, timeout time.Duration) (string, error) {
+	if err := terminate(meta); err != nil {
+		return "", err
+	}
+	if waitGone(meta, timeout) {
+		return stopClean, nil
+	}
+
+	if err := signalDaemon(meta, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return "", fmt.Errorf("signal SIGKILL to %d: %w", meta.PID, err)
+	}
+	if waitGone(meta, killGrace) {
+		return stopForced, nil
+	}
+
+	return "", fmt.Errorf("PID %d still alive after SIGKILL", meta.PID)
+}
+
+// groupAlive reports whether the daemon or any member of its process group still exists.
+// A PGID is only handed out again once its group is empty, so a reused PID means the group is gone;
+// a group lilith may not signal is not one it spawned
+func groupAlive(meta *DaemonMeta) bool {
+	if isDaemonActive(meta) {
+		return true
+	}
+	if meta.PGID <= 0 || pidReused(meta) {
+		return false
+	}
+	return syscall.Kill(-meta.PGID, syscall.Signal(0)) == nil
+}
+
+// waitGone polls until the daemon's group has exited or timeout elapses
+func waitGone(meta *DaemonMeta, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for groupAlive(meta) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// terminate sends SIGTERM to the daemon's process group. Returns nil if the process is already gone.
+func terminate(meta *DaemonMeta) error {
+	if err := signalDaemon(meta, syscall.SIGTERM); err != nil {
 		// On Unix: ESRCH == “no such process”
 		// os.ErrProcessDone == “process already finished”
 		// On Windows: Signal isn't really supported, so ignore all errors
@@ -173,30 +311,29 @@ func terminate(pid int) error {
 			runtime.GOOS == "windows":
 			return nil
 		default:
-			return fmt.Errorf("signal SIGTERM to %d: %w", pid, err)
+			return fmt.Errorf("signal SIGTERM to %d: %w", meta.PID, err)
 		}
 	}
 
+	// a frozen group holds SIGTERM pending until it is resumed
+	_ = signalDaemon(meta, syscall.SIGCONT)
 	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// slayAllDaemons & slayGroupDaemons stop dependents before the daemons they depend on
 func slayAllDaemons() {
-	files := mustListDaemonMetaFiles()
-	for _, file := range files {
-		name := nameFrom(file)
-		slaySingleDaemon(name)
+	ordered := mustOrderedDaemons("")
+	for i := len(ordered) - 1; i >= 0; i-- {
+		slayDaemon(ordered[i])
 	}
 }
 
 func slayGroupDaemons(group string) {
-	files := mustListDaemonMetaFiles()
-	for _, metaPath := range files {
-		if matchesGroup(metaPath, group) {
-			name := nameFrom(metaPath)
-			slaySingleDaemon(name)
-		}
+	ordered := mustOrderedDaemons(group)
+	for i := len(ordered) - 1; i >= 0; i-- {
+		slayDaemon(ordered[i])
 	}
 }
 
@@ -19,10 +19,17 @@ package cmd
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 import (
+	"bufio"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
+	"os/signal"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/DanielRivasMD/domovoi"
 	"github.com/DanielRivasMD/horus"
 	"github.com/spf13/cobra"
 	"github.com/ttacon/chalk"
@@ -31,12 +38,12 @@ import (
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var summonCmd = &cobra.Command{
-	Use:     "summon " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon]")),
+	Use:     "summon " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon...]")),
 	Short:   "View daemon logs",
 	Long:    helpSummon,
 	Example: exampleSummon,
 
-	Args:              cobra.ExactArgs(1),
+	Args:              cobra.ArbitraryArgs,
 	ValidArgsFunction: completeDaemonNames,
 
 	Run: runSummon,
@@ -45,14 +52,42 @@ var summonCmd = &cobra.Command{
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var (
-	follow bool
+	follow      bool
+	summonLines int
+	summonSince string
+	summonGrep  string
+	summonGroup string
+	summonAll   bool
 )
 
+const (
+	// followLines is how much history --follow shows before streaming, unless --lines says otherwise
+	followLines = 10
+	// rescanInterval is how often a multiplexed --follow looks for daemons invoked or slain meanwhile
+	rescanInterval = time.Second
+)
+
+// prefixColors tell multiplexed daemons apart
+var prefixColors = []chalk.Color{chalk.Cyan, chalk.Green, chalk.Yellow, chalk.Magenta, chalk.Blue, chalk.Red}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func init() {
 	rootCmd.AddCommand(summonCmd)
-	summonCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Continuously watch the log file")
+	summonCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Continuously watch the log file, across truncation & rotation")
+	summonCmd.Flags().IntVarP(&summonLines, "lines", "n", 0, fmt.Sprintf("Show only the last N lines (default all, %d with --follow)", followLines))
+	summonCmd.Flags().StringVar(&summonSince, "since", "", "Show only lines written since a duration ago (e.g. 10m) or a time (RFC 3339)")
+	summonCmd.Flags().StringVar(&summonGrep, "grep", "", "Show only lines matching a regular expression, highlighting matches")
+	summonCmd.Flags().StringVar(&summonGroup, "group", "", "Interleave the logs of every daemon in a group")
+	summonCmd.Flags().BoolVar(&summonAll, "all", false, "Interleave the logs of every daemon")
+
+	summonCmd.MarkFlagsMutuallyExclusive("group", "all")
+
+	horus.CheckErr(
+		summonCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups),
+		horus.WithOp("summon.init"),
+		horus.WithMessage("registering config completion"),
+	)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -60,44 +95,298 @@ func init() {
 var helpSummon = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
-	"Display daemon log output\n" +
-	"Pass --follow to stream in real time",
+	"Display daemon log output, through $PAGER when stdout is a terminal\n"+
+		"Several daemons, a --group or --all are interleaved in time order, each line prefixed by its daemon\n"+
+		"Pass --follow to stream in real time, picking up daemons invoked & dropping those slain meanwhile\n"+
+		"--since relies on lines starting with a timestamp; lines without one count as written with the line before",
 )
 
 var exampleSummon = formatExample(
 	"lilith",
 	[]string{"summon", "helix", "--follow"},
+	[]string{"summon", "helix", "--lines", "50", "--grep", "error"},
+	[]string{"summon", "helix", "--since", "10m"},
+	[]string{"summon", "helix", "goku"},
+	[]string{"summon", "--group", "<forge>", "--follow"},
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func runSummon(cmd *cobra.Command, args []string) {
 	const op = "lilith.summon"
-	name := args[0]
 
-	meta, err := loadMeta(name)
-	horus.CheckErr(err,
-		horus.WithOp(op),
-		horus.WithMessage(fmt.Sprintf("loading metadata for %q", name)),
+	if len(args) > 0 && (summonGroup != "" || summonAll) {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "cli_error", "pass daemons, --group or --all, not several", nil, nil))
+	}
+	if len(args) == 0 && summonGroup == "" && !summonAll {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "cli_error", "name a daemon, or pass --group or --all", nil, nil))
+	}
+
+	filter, err := summonFilter()
+	horus.CheckErr(err, horus.WithOp(op), horus.WithCategory("cli_error"), horus.WithMessage("parsing filters"))
+
+	metas, err := summonTargets(args, true)
+	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("loading daemons"))
+	if len(metas) == 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "env_error", "no daemons found", nil, map[string]any{"group": summonGroup}))
+	}
+
+	out := newSummonWriter(metas, len(metas) > 1 || summonGroup != "" || summonAll, filter)
+
+	n := summonLines
+	if follow && !cmd.Flags().Changed("lines") {
+		n = followLines
+	}
+	var (
+		logs      [][]daemonLine
+		offsets   = map[string]int64{}
+		timelines = map[string]*timeline{}
 	)
+	for _, meta := range metas {
+		tl := &timeline{}
+		lines, offset, err := readLog(meta.LogPath, n, filter, tl)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("reading log of %q", meta.Name)))
+		logs = append(logs, tagLines(meta.Name, lines))
+		offsets[meta.Name], timelines[meta.Name] = offset, tl
+	}
+	lines := mergeLogs(logs, n)
 
-	if follow {
+	if !follow {
 		horus.CheckErr(
-			domovoi.ExecCmd("tail", "-f", meta.LogPath),
-			horus.WithOp(op),
-			horus.WithMessage("streaming log"),
-		)
-	} else {
-		pager := os.Getenv("PAGER")
-		if pager == "" {
-			pager = "less"
-		}
-		horus.CheckErr(
-			domovoi.ExecCmd(pager, "--paging", "always", meta.LogPath),
+			withPager(func(w io.Writer) error { return out.writeAll(w, lines) }),
 			horus.WithOp(op),
 			horus.WithMessage("paging log"),
 		)
+		return
+	}
+
+	horus.CheckErr(out.writeAll(os.Stdout, lines), horus.WithOp(op), horus.WithMessage("printing log"))
+	followDaemons(args, metas, offsets, timelines, filter, out)
+}
+
+// summonTargets loads the daemons named on the command line, or those of --group or --all.
+// Named daemons must exist up front; strict off, as when rescanning, missing ones are skipped
+func summonTargets(names []string, strict bool) ([]*DaemonMeta, error) {
+	var metas []*DaemonMeta
+	if len(names) > 0 {
+		for _, name := range names {
+			meta, err := loadMeta(name)
+			if err != nil {
+				if strict {
+					return nil, err
+				}
+				continue
+			}
+			metas = append(metas, meta)
+		}
+		return metas, nil
+	}
+
+	for _, path := range mustListDaemonMetaFiles() {
+		if summonGroup != "" && !matchesGroup(path, summonGroup) {
+			continue
+		}
+		meta, err := loadMeta(nameFrom(path))
+		if err != nil {
+			// slain while listing
+			continue
+		}
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+// followDaemons streams every log until interrupted, rescanning the targets for daemons invoked or slain
+func followDaemons(names []string, metas []*DaemonMeta, offsets map[string]int64, timelines map[string]*timeline, filter logFilter, out *summonWriter) {
+	const op = "summon.follow"
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	following := map[string]chan struct{}{}
+	start := func(meta *DaemonMeta, offset int64, tl *timeline) {
+		stop := make(chan struct{})
+		following[meta.Name] = stop
+		go func() {
+			if err := followLog(meta.LogPath, offset, filter, tl, out.emitter(meta.Name), stop); err != nil {
+				out.notice(meta.Name, horus.Wrap(err, op, "following log").Error())
+			}
+		}()
+	}
+	for _, meta := range metas {
+		start(meta, offsets[meta.Name], timelines[meta.Name])
+	}
+
+	ticker := time.NewTicker(rescanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sigs:
+			for _, stop := range following {
+				close(stop)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		current, _ := summonTargets(names, false)
+		seen := map[string]bool{}
+		for _, meta := range current {
+			seen[meta.Name] = true
+			if _, ok := following[meta.Name]; !ok {
+				out.notice(meta.Name, "invoked, following")
+				// a new daemon starts a fresh log
+				start(meta, 0, &timeline{})
+			}
+		}
+		for name, stop := range following {
+			if !seen[name] {
+				close(stop)
+				delete(following, name)
+				out.notice(name, "slain, dropped")
+			}
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// daemonLine is a log line tagged with the daemon that wrote it
+type daemonLine struct {
+	daemon string
+	logLine
+}
+
+func tagLines(name string, lines []logLine) []daemonLine {
+	out := make([]daemonLine, len(lines))
+	for i, l := range lines {
+		out[i] = daemonLine{daemon: name, logLine: l}
+	}
+	return out
+}
+
+// mergeLogs interleaves logs by time, keeping the order within each log & preferring earlier logs on ties;
+// lines of unknown time go first. At most the last n lines are kept when n > 0
+func mergeLogs(logs [][]daemonLine, n int) []daemonLine {
+	var out []daemonLine
+	heads := make([]int, len(logs))
+	for {
+		pick := -1
+		for i, log := range logs {
+			if heads[i] == len(log) {
+				continue
+			}
+			if pick < 0 || log[heads[i]].at.Before(logs[pick][heads[pick]].at) {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			break
+		}
+		out = append(out, logs[pick][heads[pick]])
+		heads[pick]++
+	}
+	if n > 0 && len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out
+}
+
+// summonWriter prints log lines, prefixed by a coloured [daemon] tag when several daemons are shown
+type summonWriter struct {
+	mu        sync.Mutex
+	multiplex bool
+	width     int
+	filter    logFilter
+}
+
+func newSummonWriter(metas []*DaemonMeta, multiplex bool, filter logFilter) *summonWriter {
+	w := &summonWriter{multiplex: multiplex, filter: filter}
+	for _, meta := range metas {
+		w.width = max(w.width, len(meta.Name)+2)
+	}
+	return w
+}
+
+// prefix tags a line with its daemon
+func (w *summonWriter) prefix(name string) string {
+	if !w.multiplex {
+		return ""
+	}
+	return padCell(paint(daemonColor(name), "["+name+"]"), w.width) + " "
+}
+
+// daemonColor picks a colour from the name, so a daemon keeps it across sessions
+func daemonColor(name string) chalk.Color {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return prefixColors[h.Sum32()%uint32(len(prefixColors))]
+}
+
+func (w *summonWriter) format(l daemonLine) string {
+	return w.prefix(l.daemon) + w.filter.render(l.text)
+}
+
+func (w *summonWriter) writeAll(out io.Writer, lines []daemonLine) error {
+	bw := bufio.NewWriter(out)
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(bw, w.format(l)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// emitter prints lines of one daemon as they are followed, one whole line at a time
+func (w *summonWriter) emitter(name string) func(logLine) {
+	return func(l logLine) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		fmt.Println(w.format(daemonLine{daemon: name, logLine: l}))
+	}
+}
+
+// notice reports a change in the followed daemons on stderr, keeping stdout to log lines
+func (w *summonWriter) notice(name, message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "%s %s\n", paint(daemonColor(name), "["+name+"]"), message)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// summonFilter builds the line filter from --since & --grep
+func summonFilter() (logFilter, error) {
+	var filter logFilter
+	if summonSince != "" {
+		since, err := parseSince(summonSince)
+		if err != nil {
+			return filter, err
+		}
+		filter.since = since
+	}
+	if summonGrep != "" {
+		re, err := regexp.Compile(summonGrep)
+		if err != nil {
+			return filter, fmt.Errorf("--grep: %w", err)
+		}
+		filter.grep = re
+	}
+	return filter, nil
+}
+
+// parseSince accepts a duration back from now, or an RFC 3339 or "2006-01-02 15:04:05" time
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+		return t, nil
 	}
+	return time.Time{}, fmt.Errorf("--since %q is neither a duration nor a time", s)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -20,15 +20,12 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/DanielRivasMD/domovoi"
 	"github.com/DanielRivasMD/horus"
 	"github.com/spf13/cobra"
-	"github.com/ttacon/chalk"
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -46,6 +43,8 @@ var tallyCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(tallyCmd)
+
+	addOutputFlag(tallyCmd)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -53,12 +52,17 @@ func init() {
 var helpTally = formatHelp(
 	"Daniel Rivas",
 	"danielrivasmd@gmail.com",
-	"List all daemons invoked, showing group, PID, start time, and current status",
+	"List all daemons invoked, showing group, PID, start time, and current status\n"+
+		"Dead daemons show how their watcher last exited\n"+
+		"Daemons paused with freeze show as frozen; those stopped any other way show as limbo\n"+
+		"Use --output json or yaml for scripts; colour is dropped when stdout is not a terminal or NO_COLOR is set",
 )
 
 var exampleTally = formatExample(
 	"lilith",
 	[]string{"tally"},
+	[]string{"tally", "--output", "wide"},
+	[]string{"tally", "--output", "json"},
 )
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -71,20 +75,15 @@ func RunTally(cmd *cobra.Command, args []string) {
 	entries, err := domovoi.ReadDir(dir, verbose)
 	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("reading daemon directory"))
 
-	// 2) Print header
-	fmt.Printf(
-		"%-20s %-15s %-6s %-20s %s\n",
-		"NAME", "GROUP", "PID", "INVOKED", "STATUS",
-	)
-
-	// 3) Iterate over metadata files
+	// 2) Iterate over metadata files, skipping histories kept alongside
+	var views []DaemonView
 	for _, e := range entries {
-		if e.IsDir() {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
 			continue
 		}
 		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
 
-		// 4) Load metadata
+		// 3) Load metadata
 		meta, err := loadMeta(name)
 		if err != nil {
 			// skip entries that fail to parse
@@ -92,28 +91,12 @@ func RunTally(cmd *cobra.Command, args []string) {
 			continue
 		}
 
-		// 5) Determine process status via `ps` (detect T=stopped/paused)
-		status := chalk.Red.Color("dead")
-		stateOut, err := exec.Command("ps", "-o", "state=", "-p", strconv.Itoa(meta.PID)).Output()
-		if err == nil {
-			state := strings.TrimSpace(string(stateOut))
-			switch {
-			case strings.HasPrefix(state, "T"):
-				status = chalk.Yellow.Color("limbo")
-			default:
-				status = chalk.Green.Color("alive")
-			}
-		}
-
-		// 6) Format invoked timestamp
-		invoked := meta.InvokedAt.Format("2006-01-02 15:04:05")
-
-		// 7) Print row
-		fmt.Printf(
-			"%-20s %-15s %-6d %-20s %s\n",
-			meta.Name, meta.Group, meta.PID, invoked, status,
-		)
+		// 4) Derive process status
+		views = append(views, viewOf(meta))
 	}
+
+	// 5) Render in the requested format
+	renderViews(views)
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var thawCmd = &cobra.Command{
+	Use:     "thaw " + chalk.Dim.TextStyle(chalk.Italic.TextStyle("[daemon]")),
+	Short:   "Resume frozen daemon",
+	Long:    helpThaw,
+	Example: exampleThaw,
+
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDaemonNames,
+
+	Run: RunThaw,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(thawCmd)
+
+	thawCmd.Flags().String("group", "", "Thaw all daemons belonging to a specific group")
+	thawCmd.Flags().Bool("all", false, "Thaw all daemons")
+	addOutputFlag(thawCmd)
+
+	horus.CheckErr(thawCmd.RegisterFlagCompletionFunc("group", completeWorkflowGroups), horus.WithOp("thaw.init"), horus.WithMessage("registering config completion"))
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpThaw = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Resume daemon execution paused by freeze using SIGCONT",
+)
+
+var exampleThaw = formatExample(
+	"lilith",
+	[]string{"thaw", "helix"},
+	[]string{"thaw", "--group", "<forge>"},
+	[]string{"thaw", "--all"},
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func RunThaw(cmd *cobra.Command, args []string) {
+	const op = "lilith.thaw"
+
+	group, _ := cmd.Flags().GetString("group")
+	all, _ := cmd.Flags().GetBool("all")
+
+	switch {
+	case all:
+		thawAllDaemons()
+	case group != "":
+		thawGroupDaemons(group)
+	case len(args) == 1:
+		// Single daemon thaw
+		name := args[0]
+
+		// 1) Load metadata
+		meta, err := loadMeta(name)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("loading metadata for %q", name)))
+
+		// 2) Resume process & clear the pause & confirm it
+		thawAndReport(meta)
+	default:
+		horus.CheckErr(horus.NewCategorizedHerror(op, "validation", "must provide a daemon name or --all / --group", nil, nil))
+	}
+	finishReports(op)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// thawDaemon resumes the daemon's process group & clears the frozen mark.
+// Both happen on a fresh copy of the metadata under the lock, keeping what a concurrent vigil restart recorded
+func thawDaemon(meta *DaemonMeta) error {
+	var sigErr error
+	fresh, err := updateMeta(meta.Name, func(current *DaemonMeta) bool {
+		if sigErr = signalDaemon(current, syscall.SIGCONT); sigErr != nil {
+			return false
+		}
+		current.Frozen = false
+		current.FrozenAt = time.Time{}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	*meta = *fresh
+	return sigErr
+}
+
+// thawAndReport thaws a daemon, reporting a failure rather than exiting so the others proceed
+func thawAndReport(meta *DaemonMeta) {
+	if err := thawDaemon(meta); err != nil {
+		reportFailure(meta, err, fmt.Sprintf("thawing %q", meta.Name))
+		return
+	}
+	report(meta, "thawed", fmt.Sprintf("thawed daemon %q", meta.Name))
+}
+
+func thawGroupDaemons(group string) {
+	files := mustListDaemonMetaFiles()
+	for _, path := range files {
+		if matchesGroup(path, group) {
+			thawAndReport(mustLoadMeta(path))
+		}
+	}
+}
+
+func thawAllDaemons() {
+	files := mustListDaemonMetaFiles()
+	for _, path := range files {
+		thawAndReport(mustLoadMeta(path))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,288 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var vigilCmd = &cobra.Command{
+	Use:     "vigil",
+	Short:   "Supervise daemons & restart the fallen",
+	Long:    helpVigil,
+	Example: exampleVigil,
+
+	Args: cobra.NoArgs,
+
+	Run: runVigil,
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	vigilInterval time.Duration
+	vigilOnce     bool
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootCmd.AddCommand(vigilCmd)
+
+	vigilCmd.Flags().DurationVar(&vigilInterval, "interval", 5*time.Second, "How often daemons are checked")
+	vigilCmd.Flags().BoolVar(&vigilOnce, "once", false, "Check every daemon once & exit")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var helpVigil = formatHelp(
+	"Daniel Rivas",
+	"danielrivasmd@gmail.com",
+	"Keep watch over every invoked daemon, restarting those that died according to their restart policy\n"+
+		"Restarts back off exponentially & stop once max_restarts is reached within restart_window",
+)
+
+var exampleVigil = formatExample(
+	"lilith",
+	[]string{"vigil"},
+	[]string{"vigil", "--interval", "10s"},
+	[]string{"vigil", "--once"},
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// restart policies accepted in `restart`
+const (
+	restartAlways    = "always"
+	restartOnFailure = "on-failure"
+	restartNever     = "never"
+)
+
+const (
+	// defaultMaxRestarts applies when a workflow sets no max_restarts
+	defaultMaxRestarts = 5
+	// defaultRestartWindow applies when a workflow sets no restart_window
+	defaultRestartWindow = 10 * time.Minute
+	// backoffBase is the delay after the first restart, doubled for each further one in the window
+	backoffBase = time.Second
+	// backoffCap bounds the exponential backoff
+	backoffCap = 5 * time.Minute
+)
+
+// validRestartPolicy rejects unknown policies; empty means never
+func validRestartPolicy(policy string) error {
+	switch policy {
+	case "", restartAlways, restartOnFailure, restartNever:
+		return nil
+	}
+	return horus.NewCategorizedHerror(
+		"vigil.policy", "config_error", "unknown restart policy", nil,
+		map[string]any{"restart": policy, "available": restartAlways + ", " + restartOnFailure + ", " + restartNever},
+	)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func runVigil(cmd *cobra.Command, args []string) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+
+	ticker := time.NewTicker(vigilInterval)
+	defer ticker.Stop()
+
+	for {
+		reapChildren()
+		for _, path := range mustListDaemonMetaFiles() {
+			meta, err := loadMeta(nameFrom(path))
+			if err != nil {
+				// metadata may vanish mid-slay
+				continue
+			}
+			watchOver(meta, time.Now())
+		}
+
+		if vigilOnce {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-sigs:
+			return
+		}
+	}
+}
+
+// watchOver applies the restart policy to a single daemon
+func watchOver(meta *DaemonMeta, now time.Time) {
+	const op = "lilith.vigil"
+
+	// intentional pauses & stops in progress are not failures
+	if meta.Frozen || meta.Stopping || groupAlive(meta) {
+		return
+	}
+
+	// a shim killed outright leaves no record, which counts as a failure
+	rec := lastExit(meta)
+	failed := rec == nil || rec.Failed()
+
+	// slay, freeze or rekindle may have acted since meta was read; what they recorded wins
+	seen := meta.InvokedAt
+	unchanged := func(current *DaemonMeta) bool {
+		return !current.Frozen && !current.Stopping && current.InvokedAt.Equal(seen)
+	}
+
+	// record the death once
+	if meta.LastExitAt.Before(meta.InvokedAt) {
+		exit := "killed without exit record"
+		if rec != nil {
+			exit = rec.Summary()
+		}
+		current, err := updateMeta(meta.Name, func(current *DaemonMeta) bool {
+			if !unchanged(current) {
+				return false
+			}
+			current.LastExit, current.LastExitAt = exit, now
+			return true
+		})
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "recording exit"))
+			}
+			return
+		}
+		if !unchanged(current) {
+			return
+		}
+		meta = current
+		vigilLog(meta, paint(chalk.Red, "died")+": "+meta.LastExit)
+	}
+
+	switch {
+	case meta.Restart == restartAlways:
+	case meta.Restart == restartOnFailure && failed:
+	default:
+		return
+	}
+
+	window := defaultRestartWindow
+	if d, err := time.ParseDuration(meta.RestartWindow); err == nil && d > 0 {
+		window = d
+	}
+	limit := meta.MaxRestarts
+	if limit <= 0 {
+		limit = defaultMaxRestarts
+	}
+
+	// forget restarts that slid out of the window
+	var recent []time.Time
+	for _, at := range meta.Restarts {
+		if now.Sub(at) < window {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) >= limit {
+		return
+	}
+	if len(recent) > 0 && now.Before(recent[len(recent)-1].Add(backoff(len(recent)))) {
+		return
+	}
+
+	// claim the restart, unless the daemon was slain or stopped meanwhile
+	current, err := updateMeta(meta.Name, func(current *DaemonMeta) bool {
+		if !unchanged(current) {
+			return false
+		}
+		current.RestartCount++
+		current.Restarts = append(recent, now)
+		return true
+	})
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, horus.Wrap(err, op, "recording restart"))
+		}
+		return
+	}
+	if !unchanged(current) {
+		return
+	}
+	meta = current
+
+	pid, err := reviveDaemon(meta)
+	if err != nil {
+		// keep the attempt so the backoff applies to failing spawns as well
+		_, _ = updateMeta(meta.Name, func(current *DaemonMeta) bool {
+			if !unchanged(current) {
+				return false
+			}
+			current.LastExit, current.LastExitAt = "restart failed", now
+			return true
+		})
+		fmt.Fprintln(os.Stderr, horus.Wrap(err, op, fmt.Sprintf("restarting %q", meta.Name)))
+		return
+	}
+
+	msg := fmt.Sprintf("restarted with PID %d (%d/%d in %s)", pid, len(meta.Restarts), limit, window)
+	if len(meta.Restarts) >= limit {
+		msg += ", " + paint(chalk.Yellow, "crash loop: no further restarts until the window slides")
+	}
+	vigilLog(meta, msg)
+}
+
+// reapChildren collects watchers vigil restarted that have since exited.
+// Released children stay zombies, & thus look alive, until their parent waits on them
+func reapChildren() {
+	var status syscall.WaitStatus
+	for {
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if pid <= 0 || err != nil {
+			return
+		}
+	}
+}
+
+// backoff is the delay required after the n-th restart within the window
+func backoff(n int) time.Duration {
+	d := backoffBase
+	for i := 1; i < n && d < backoffCap; i++ {
+		d *= 2
+	}
+	if d > backoffCap {
+		d = backoffCap
+	}
+	return d
+}
+
+func vigilLog(meta *DaemonMeta, msg string) {
+	fmt.Printf("%s %s %s\n", time.Now().Format("2006-01-02 15:04:05"), paint(chalk.Cyan, meta.Name), msg)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
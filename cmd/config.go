@@ -0,0 +1,392 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DanielRivasMD/domovoi"
+	"github.com/DanielRivasMD/horus"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Workflow mirrors a [workflows.<name>] table, in the key order written by --save
+type Workflow struct {
+	Watch         []string          `toml:"watch"`
+	Script        string            `toml:"script,omitempty"`
+	Run           string            `toml:"run,omitempty"`
+	Interpreter   string            `toml:"interpreter,omitempty"`
+	Args          []string          `toml:"args,omitempty"`
+	Cwd           string            `toml:"cwd,omitempty"`
+	Env           map[string]string `toml:"env,inline,omitempty"`
+	EnvFile       string            `toml:"env_file,omitempty"`
+	ChangedFile   bool              `toml:"changed_file,omitempty"`
+	LogFormat     string            `toml:"log_format,omitempty"`
+	LogMaxSize    string            `toml:"log_max_size,omitempty"`
+	LogMaxFiles   int               `toml:"log_max_files,omitempty"`
+	LogMaxAge     string            `toml:"log_max_age,omitempty"`
+	LogCompress   bool              `toml:"log_compress,omitempty"`
+	KeepLogs      bool              `toml:"keep_logs,omitempty"`
+	Backend       string            `toml:"backend,omitempty"`
+	Debounce      string            `toml:"debounce,omitempty"`
+	Ignore        []string          `toml:"ignore,omitempty"`
+	Extensions    []string          `toml:"extensions,omitempty"`
+	Events        []string          `toml:"events,omitempty"`
+	StopTimeout   string            `toml:"stop_timeout,omitempty"`
+	Restart       string            `toml:"restart,omitempty"`
+	MaxRestarts   int               `toml:"max_restarts,omitempty"`
+	RestartWindow string            `toml:"restart_window,omitempty"`
+	DependsOn     []string          `toml:"depends_on,omitempty"`
+	OnSuccess     []string          `toml:"on_success,omitempty"`
+	OnFailure     []string          `toml:"on_failure,omitempty"`
+	AllowOverlap  bool              `toml:"allow_overlap,omitempty"`
+}
+
+// workflowOf captures the settings of a daemon so it can be invoked again by name
+func workflowOf(meta *DaemonMeta) Workflow {
+	return Workflow{
+		Watch:         meta.WatchPaths,
+		Script:        meta.ScriptPath,
+		Run:           meta.Run,
+		Interpreter:   meta.Interpreter,
+		Args:          meta.Args,
+		Cwd:           meta.Cwd,
+		Env:           meta.Env,
+		EnvFile:       meta.EnvFile,
+		ChangedFile:   meta.ChangedFile,
+		LogFormat:     meta.LogFormat,
+		LogMaxSize:    meta.LogMaxSize,
+		LogMaxFiles:   meta.LogMaxFiles,
+		LogMaxAge:     meta.LogMaxAge,
+		LogCompress:   meta.LogCompress,
+		KeepLogs:      meta.KeepLogs,
+		Backend:       meta.Backend,
+		Debounce:      meta.Debounce,
+		Ignore:        meta.Ignore,
+		Extensions:    meta.Extensions,
+		Events:        meta.Events,
+		StopTimeout:   meta.StopTimeout,
+		Restart:       meta.Restart,
+		MaxRestarts:   meta.MaxRestarts,
+		RestartWindow: meta.RestartWindow,
+		DependsOn:     meta.DependsOn,
+		OnSuccess:     meta.OnSuccess,
+		OnFailure:     meta.OnFailure,
+		AllowOverlap:  meta.AllowOverlap,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// configDir returns ~/.lilith/config
+func configDir() string {
+	return filepath.Join(home, ".lilith", "config")
+}
+
+// settingsPath returns ~/.lilith/lilith.toml, holding defaults for the log keys of every workflow
+func settingsPath() string {
+	return filepath.Join(home, ".lilith", "lilith.toml")
+}
+
+// loadSettings reads ~/.lilith/lilith.toml; a missing file sets nothing
+func loadSettings() (*viper.Viper, error) {
+	const op = "config.settings"
+
+	v := viper.New()
+	v.SetConfigFile(settingsPath())
+	if _, err := os.Stat(settingsPath()); os.IsNotExist(err) {
+		return v, nil
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "reading settings", err, map[string]any{"path": settingsPath()})
+	}
+	return v, nil
+}
+
+// configFiles lists ~/.lilith/config/*.toml; a missing directory holds no files
+func configFiles() ([]string, error) {
+	const op = "config.files"
+
+	fis, err := os.ReadDir(configDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "reading config dir", err, map[string]any{"dir": configDir()})
+	}
+
+	var out []string
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".toml") {
+			continue
+		}
+		out = append(out, filepath.Join(configDir(), fi.Name()))
+	}
+	return out, nil
+}
+
+// groupOf names the group of workflows in a config file after the file itself
+func groupOf(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// findWorkflow returns the table of a workflow & the file defining it, or a nil table when none does
+func findWorkflow(name string) (*viper.Viper, string, error) {
+	files, err := configFiles()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, path := range files {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			continue
+		}
+		if v.IsSet("workflows." + name) {
+			return v.Sub("workflows." + name), path, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// workflowFrom reads a [workflows.<name>] table as found by viper
+func workflowFrom(v *viper.Viper) (Workflow, error) {
+	wf := Workflow{
+		Watch:         configList(v, "watch"),
+		Script:        v.GetString("script"),
+		Run:           v.GetString("run"),
+		Interpreter:   v.GetString("interpreter"),
+		Args:          configList(v, "args"),
+		Cwd:           v.GetString("cwd"),
+		EnvFile:       v.GetString("env_file"),
+		ChangedFile:   v.GetBool("changed_file"),
+		LogFormat:     v.GetString("log_format"),
+		LogMaxSize:    v.GetString("log_max_size"),
+		LogMaxAge:     v.GetString("log_max_age"),
+		LogCompress:   v.GetBool("log_compress"),
+		KeepLogs:      v.GetBool("keep_logs"),
+		Backend:       v.GetString("backend"),
+		Debounce:      v.GetString("debounce"),
+		Ignore:        configList(v, "ignore"),
+		Extensions:    configList(v, "extensions"),
+		Events:        configList(v, "events"),
+		StopTimeout:   v.GetString("stop_timeout"),
+		Restart:       v.GetString("restart"),
+		RestartWindow: v.GetString("restart_window"),
+		DependsOn:     configList(v, "depends_on"),
+		OnSuccess:     configList(v, "on_success"),
+		OnFailure:     configList(v, "on_failure"),
+		AllowOverlap:  v.GetBool("allow_overlap"),
+	}
+	if v.IsSet("max_restarts") {
+		n, err := strconv.Atoi(v.GetString("max_restarts"))
+		if err != nil {
+			return wf, horus.NewCategorizedHerror("config.workflow", "config_error", "parsing max_restarts", err, nil)
+		}
+		wf.MaxRestarts = n
+	}
+	if v.IsSet("log_max_files") {
+		n, err := strconv.Atoi(v.GetString("log_max_files"))
+		if err != nil {
+			return wf, horus.NewCategorizedHerror("config.workflow", "config_error", "parsing log_max_files", err, nil)
+		}
+		wf.LogMaxFiles = n
+	}
+	return wf, nil
+}
+
+// configList reads a key holding either a single string or an array of them;
+// viper would split a lone string on whitespace, breaking paths with spaces
+func configList(v *viper.Viper, key string) []string {
+	if s, ok := v.Get(key).(string); ok {
+		return []string{s}
+	}
+	return v.GetStringSlice(key)
+}
+
+// workflowEnv reads the env table of a workflow straight from its file,
+// as viper lowercases keys & environment variables are case sensitive
+func workflowEnv(path, name string) (map[string]string, error) {
+	const op = "config.env"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "reading config file", err, map[string]any{"path": path})
+	}
+	var doc struct {
+		Workflows map[string]struct {
+			Env map[string]any `toml:"env"`
+		} `toml:"workflows"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "decoding config file", err, map[string]any{"path": path})
+	}
+
+	// viper hands out workflow names lowercased too
+	for key, wf := range doc.Workflows {
+		if !strings.EqualFold(key, name) || len(wf.Env) == 0 {
+			continue
+		}
+		env := map[string]string{}
+		for k, v := range wf.Env {
+			env[k] = fmt.Sprint(v)
+		}
+		return env, nil
+	}
+	return nil, nil
+}
+
+// workflowEntry is a workflow together with the file declaring it
+type workflowEntry struct {
+	Name  string
+	Group string
+	Path  string
+	Workflow
+}
+
+// loadWorkflows collects the workflows of every config file, optionally restricted to one group.
+// A workflow declared twice is reported, as invoke would silently pick the first file
+func loadWorkflows(group string) ([]workflowEntry, error) {
+	const op = "config.loadWorkflows"
+
+	files, err := configFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []workflowEntry
+	seen := map[string]string{}
+	for _, path := range files {
+		if group != "" && groupOf(path) != group {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "reading config file", err, map[string]any{"path": path})
+		}
+
+		var names []string
+		for name := range v.GetStringMap("workflows") {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if prev, ok := seen[name]; ok {
+				return nil, horus.NewCategorizedHerror(
+					op, "config_error", "workflow declared in two files", nil,
+					map[string]any{"workflow": name, "files": prev + ", " + path},
+				)
+			}
+			seen[name] = path
+
+			wf, err := workflowFrom(v.Sub("workflows." + name))
+			if err != nil {
+				return nil, horus.Wrap(err, op, fmt.Sprintf("reading workflow %q", name))
+			}
+			if wf.Env, err = workflowEnv(path, name); err != nil {
+				return nil, err
+			}
+			out = append(out, workflowEntry{Name: name, Group: groupOf(path), Path: path, Workflow: wf})
+		}
+	}
+	return out, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// resolveConfigPath places a bare file name inside ~/.lilith/config & appends a missing .toml extension
+func resolveConfigPath(file string) (string, error) {
+	if filepath.Ext(file) == "" {
+		file += ".toml"
+	}
+	if !strings.ContainsRune(file, filepath.Separator) {
+		return filepath.Join(configDir(), file), nil
+	}
+	return expandPath(file)
+}
+
+// saveWorkflow appends a [workflows.<name>] table to path, leaving existing content & comments intact.
+// The file may lie outside ~/.lilith/config, so it is checked itself for a table of the same name
+func saveWorkflow(path, name string, wf Workflow) error {
+	const op = "config.saveWorkflow"
+
+	if err := domovoi.CreateDir(filepath.Dir(path), false); err != nil {
+		return horus.Wrap(err, op, "creating config directory")
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var existing struct {
+			Workflows map[string]any `toml:"workflows"`
+		}
+		if err := toml.Unmarshal(data, &existing); err != nil {
+			return horus.NewCategorizedHerror(op, "config_error", "parsing config file", err, map[string]any{"path": path})
+		}
+		if _, ok := existing.Workflows[name]; ok {
+			return horus.NewCategorizedHerror(op, "config_error", "workflow already defined, invoke it with --config", nil, map[string]any{"workflow": name, "file": path})
+		}
+	} else if !os.IsNotExist(err) {
+		return horus.NewCategorizedHerror(op, "env_error", "reading config file", err, map[string]any{"path": path})
+	}
+
+	body, err := toml.Marshal(wf)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "config_error", "encoding workflow", err, map[string]any{"workflow": name})
+	}
+
+	var b strings.Builder
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[workflows.%s]\n", tomlKey(name))
+	b.Write(body)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "opening config file", err, map[string]any{"path": path})
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "writing config file", err, map[string]any{"path": path})
+	}
+	return nil
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey quotes a key unless TOML accepts it bare
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,181 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// orderByDependencies sorts items so each one follows everything it depends on, otherwise keeping
+// the input order. Several items may share a key; dependencies on keys absent from items are ignored.
+// A cycle is reported with the keys along it, e.g. "helix → language → helix"
+func orderByDependencies[T any](items []T, key func(T) string, deps func(T) []string) ([]T, error) {
+	const op = "depends.order"
+
+	var keys []string
+	members := map[string][]T{}
+	edges := map[string][]string{}
+	for _, item := range items {
+		k := key(item)
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = append(members[k], item)
+		edges[k] = append(edges[k], deps(item)...)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var (
+		ordered []T
+		path    []string
+		visit   func(k string) error
+	)
+	visit = func(k string) error {
+		switch state[k] {
+		case done:
+			return nil
+		case visiting:
+			// the cycle runs from the first visit of k down the current path & back to k
+			for i, p := range path {
+				if p == k {
+					cycle := append(append([]string{}, path[i:]...), k)
+					return horus.NewCategorizedHerror(
+						op, "config_error", "dependency cycle: "+strings.Join(cycle, " → "), nil,
+						map[string]any{"cycle": cycle},
+					)
+				}
+			}
+		}
+
+		state[k] = visiting
+		path = append(path, k)
+		for _, dep := range edges[k] {
+			if _, ok := members[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[k] = done
+		ordered = append(ordered, members[k]...)
+		return nil
+	}
+
+	for _, k := range keys {
+		if err := visit(k); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// orderDaemons sorts daemons for startup, dependencies first
+func orderDaemons(metas []*DaemonMeta) ([]*DaemonMeta, error) {
+	return orderByDependencies(metas, daemonKey, func(m *DaemonMeta) []string { return m.DependsOn })
+}
+
+// orderWorkflows sorts workflows for startup, dependencies first
+func orderWorkflows(workflows []workflowEntry) ([]workflowEntry, error) {
+	return orderByDependencies(workflows,
+		func(wf workflowEntry) string { return wf.Name },
+		func(wf workflowEntry) []string { return wf.DependsOn },
+	)
+}
+
+// daemonKey is the name other workflows use to depend on a daemon
+func daemonKey(meta *DaemonMeta) string {
+	if meta.Workflow != "" {
+		return meta.Workflow
+	}
+	return meta.Name
+}
+
+// mustOrderedDaemons loads the daemons of a group, or all of them when empty, in startup order.
+// Live daemons may depend on each other in a cycle, as config files change after invoke;
+// stopping them must still work, so such daemons fall back to invocation order with a warning
+func mustOrderedDaemons(group string) []*DaemonMeta {
+	var metas []*DaemonMeta
+	for _, path := range mustListDaemonMetaFiles() {
+		if group == "" || matchesGroup(path, group) {
+			metas = append(metas, mustLoadMeta(path))
+		}
+	}
+	ordered, err := orderDaemons(metas)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v; using invocation order\n", paint(chalk.Yellow, "WARNING:"), err)
+		ordered = metas
+		sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].InvokedAt.Before(ordered[j].InvokedAt) })
+	}
+	return ordered
+}
+
+// checkDependencyCycle follows depends_on from a workflow through the config files,
+// reporting a cycle among the workflows it reaches
+func checkDependencyCycle(name string) error {
+	const op = "depends.check"
+
+	var (
+		reached []workflowEntry
+		seen    = map[string]bool{}
+		walk    func(name string) error
+	)
+	walk = func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		v, path, err := findWorkflow(name)
+		if err != nil || v == nil {
+			return err
+		}
+		wf, err := workflowFrom(v)
+		if err != nil {
+			return horus.Wrap(err, op, fmt.Sprintf("reading workflow %q", name))
+		}
+		reached = append(reached, workflowEntry{Name: name, Group: groupOf(path), Path: path, Workflow: wf})
+		for _, dep := range wf.DependsOn {
+			if err := walk(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(name); err != nil {
+		return err
+	}
+	_, err := orderWorkflows(reached)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
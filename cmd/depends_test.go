@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type node struct {
+	name string
+	deps []string
+}
+
+func TestOrderByDependencies(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []node
+		want  []string
+		cycle string
+	}{
+		{
+			name:  "dependency first",
+			nodes: []node{{"b", []string{"a"}}, {"a", nil}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "independent keep input order",
+			nodes: []node{{"c", nil}, {"a", nil}, {"b", nil}},
+			want:  []string{"c", "a", "b"},
+		},
+		{
+			name:  "chain",
+			nodes: []node{{"c", []string{"b"}}, {"b", []string{"a"}}, {"a", nil}},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "shared key",
+			nodes: []node{{"b", []string{"a"}}, {"a", nil}, {"b", nil}},
+			want:  []string{"a", "b", "b"},
+		},
+		{
+			name:  "missing dependency ignored",
+			nodes: []node{{"b", []string{"ghost"}}, {"a", nil}},
+			want:  []string{"b", "a"},
+		},
+		{
+			name:  "cycle",
+			nodes: []node{{"a", []string{"b"}}, {"b", []string{"a"}}},
+			cycle: "dependency cycle: a → b → a",
+		},
+		{
+			name:  "self cycle",
+			nodes: []node{{"a", []string{"a"}}},
+			cycle: "dependency cycle: a → a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := orderByDependencies(tt.nodes,
+				func(n node) string { return n.name },
+				func(n node) []string { return n.deps },
+			)
+			if tt.cycle != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.cycle) {
+					t.Fatalf("error = %v, want %q", err, tt.cycle)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, n := range ordered {
+				got = append(got, n.name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// variables describing a run to its script, set the same way under every backend;
+// lists hold one path per line
+const (
+	envDaemon      = "LILITH_DAEMON"
+	envGroup       = "LILITH_GROUP"
+	envWatch       = "LILITH_WATCH"
+	envLog         = "LILITH_LOG"
+	envRunID       = "LILITH_RUN_ID"
+	envChanged     = "LILITH_CHANGED"      // paths that triggered the run, empty for startup & chained runs
+	envChangedFile = "LILITH_CHANGED_FILE" // file holding the same list, with changed_file enabled
+)
+
+// runEnv returns the LILITH_* variables of a run. With changed_file enabled, the changed paths are also
+// written to a temporary file, which cleanup removes once the script exits
+func runEnv(meta *DaemonMeta, rec *RunRecord) (env []string, cleanup func(), err error) {
+	const op = "daemon.runEnv"
+
+	changed := strings.Join(rec.Trigger, "\n")
+	env = []string{
+		envDaemon + "=" + meta.Name,
+		envGroup + "=" + meta.Group,
+		envWatch + "=" + strings.Join(meta.WatchPaths, "\n"),
+		envLog + "=" + meta.LogPath,
+		envRunID + "=" + rec.ID,
+		envChanged + "=" + changed,
+	}
+	cleanup = func() {}
+	if !meta.ChangedFile {
+		return env, cleanup, nil
+	}
+
+	f, err := os.CreateTemp("", "lilith-"+meta.Name+"-*.changed")
+	if err != nil {
+		return env, cleanup, horus.NewCategorizedHerror(op, "env_error", "creating changed paths file", err, map[string]any{"daemon": meta.Name})
+	}
+	defer f.Close()
+	if changed != "" {
+		changed += "\n"
+	}
+	if _, err := f.WriteString(changed); err != nil {
+		_ = os.Remove(f.Name())
+		return env, cleanup, horus.NewCategorizedHerror(op, "env_error", "writing changed paths file", err, map[string]any{"path": f.Name()})
+	}
+	return append(env, envChangedFile+"="+f.Name()), func() { _ = os.Remove(f.Name()) }, nil
+}
+
+// applyExecContext runs c in the daemon's cwd with its env_file & env layered over the environment recorded
+// at invoke, so every spawn, whether rekindled from another shell or restarted by vigil, sees the same context.
+// Metadata written before the environment was recorded falls back to lilith's own
+func applyExecContext(c *exec.Cmd, meta *DaemonMeta) error {
+	const op = "daemon.execContext"
+
+	if meta.Cwd != "" {
+		c.Dir = meta.Cwd
+	}
+
+	env := append([]string{}, meta.Environ...)
+	if len(env) == 0 {
+		env = os.Environ()
+	}
+	if meta.EnvFile != "" {
+		vars, err := loadEnvFile(meta.EnvFile)
+		if err != nil {
+			return horus.Wrap(err, op, "loading env_file")
+		}
+		env = append(env, vars...)
+	}
+	// env wins over env_file; exec keeps the last value of a repeated key
+	keys := make([]string, 0, len(meta.Env))
+	for key := range meta.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+meta.Env[key])
+	}
+	c.Env = env
+	return nil
+}
+
+// loadEnvFile reads KEY=VALUE lines, skipping blanks & # comments, tolerating a leading
+// `export` & quotes around values, as written for shells
+func loadEnvFile(path string) ([]string, error) {
+	const op = "daemon.envFile"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "opening env file", err, map[string]any{"path": path})
+	}
+	defer f.Close()
+
+	var vars []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, horus.NewCategorizedHerror(
+				op, "config_error", "expected KEY=VALUE", nil,
+				map[string]any{"path": path, "line": n},
+			)
+		}
+		vars = append(vars, key+"="+unquote(strings.TrimSpace(value)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, horus.NewCategorizedHerror(op, "config_error", "reading env file", err, map[string]any{"path": path})
+	}
+	return vars, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseEnvFlags turns repeated --env KEY=VALUE flags into a map laid over base
+func parseEnvFlags(base map[string]string, flags []string) (map[string]string, error) {
+	const op = "daemon.envFlags"
+
+	if len(flags) == 0 {
+		return base, nil
+	}
+	env := map[string]string{}
+	for key, value := range base {
+		env[key] = value
+	}
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		if !ok || key == "" {
+			return nil, horus.NewCategorizedHerror(op, "config_error", fmt.Sprintf("expected KEY=VALUE, got %q", flag), nil, nil)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// change kinds accepted by the `events` key
+const (
+	eventCreate = "create"
+	eventModify = "modify"
+	eventRemove = "remove"
+	eventRename = "rename"
+)
+
+var eventKinds = []string{eventCreate, eventModify, eventRemove, eventRename}
+
+// watchFilter decides which changes below the watch roots wake a daemon.
+// Ignore patterns follow .gitignore loosely: without a slash they match any path segment,
+// otherwise the path relative to its root, with ** spanning directories; a leading ! re-includes
+// what an earlier pattern ignored, the last pattern matching a path deciding
+type watchFilter struct {
+	roots      []string
+	ignore     []string
+	negated    bool            // some pattern re-includes paths, so ignored directories may still hold watched files
+	extensions map[string]bool // empty allows every extension
+	events     map[string]bool // empty allows every kind
+}
+
+// newWatchFilter validates patterns, extensions & event kinds
+func newWatchFilter(roots, ignore, extensions, events []string) (*watchFilter, error) {
+	const op = "filter.new"
+
+	f := &watchFilter{
+		extensions: map[string]bool{},
+		events:     map[string]bool{},
+	}
+	for _, root := range roots {
+		f.roots = append(f.roots, filepath.Clean(root))
+	}
+	for _, pattern := range ignore {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		body, negated := strings.CutPrefix(pattern, "!")
+		if _, err := path.Match(body, ""); err != nil || body == "" {
+			return nil, horus.NewCategorizedHerror(op, "config_error", "invalid ignore pattern", err, map[string]any{"pattern": pattern})
+		}
+		f.negated = f.negated || negated
+		f.ignore = append(f.ignore, pattern)
+	}
+	for _, ext := range extensions {
+		f.extensions[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+	for _, kind := range events {
+		if !validEvent(kind) {
+			return nil, horus.NewCategorizedHerror(
+				op, "config_error", "unknown event", nil,
+				map[string]any{"event": kind, "available": strings.Join(eventKinds, ", ")},
+			)
+		}
+		f.events[kind] = true
+	}
+	return f, nil
+}
+
+func validEvent(kind string) bool {
+	for _, k := range eventKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether a change of kind to p should wake the daemon
+func (f *watchFilter) allows(p, kind string) bool {
+	if len(f.events) > 0 && !f.events[kind] {
+		return false
+	}
+	return f.allowsPath(p)
+}
+
+// allowsPath applies ignore patterns & extensions, regardless of the kind of change
+func (f *watchFilter) allowsPath(p string) bool {
+	if f.ignored(p) {
+		return false
+	}
+	if len(f.extensions) > 0 {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(p), "."))
+		return f.extensions[ext]
+	}
+	return true
+}
+
+// ignored reports whether p, or a directory holding it, matches the last ignore pattern deciding about it
+func (f *watchFilter) ignored(p string) bool {
+	rel := f.relative(p)
+	if rel == "" {
+		return false
+	}
+	segs := strings.Split(filepath.ToSlash(rel), "/")
+	ignored := false
+	for _, pattern := range f.ignore {
+		body, negated := strings.CutPrefix(pattern, "!")
+		if ignored != negated {
+			continue
+		}
+		if matchPattern(body, segs) {
+			ignored = !negated
+		}
+	}
+	return ignored
+}
+
+// prunes reports whether a walk may skip directory p & everything below it
+func (f *watchFilter) prunes(p string) bool {
+	return !f.negated && f.ignored(p)
+}
+
+// matchPattern matches one ignore pattern, without its !, against the segments of a relative path
+func matchPattern(pattern string, segs []string) bool {
+	if !strings.Contains(pattern, "/") {
+		for _, seg := range segs {
+			if ok, _ := path.Match(pattern, seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+	pats := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	// a match on a parent directory covers everything below it
+	for n := 1; n <= len(segs); n++ {
+		if matchSegments(pats, segs[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// relative returns p relative to the innermost root holding it, empty for roots themselves & outsiders
+func (f *watchFilter) relative(p string) string {
+	rel := ""
+	for _, root := range f.roots {
+		if !within(root, p) {
+			continue
+		}
+		if r, _ := filepath.Rel(root, p); rel == "" || len(r) < len(rel) {
+			rel = r
+		}
+	}
+	if rel == "." {
+		return ""
+	}
+	return rel
+}
+
+// within reports whether p is root or lies below it, both taken as cleaned paths
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, filepath.Clean(p))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// matchSegments matches path segments against pattern segments, ** standing for any number of them
+func matchSegments(pats, segs []string) bool {
+	for len(pats) > 0 {
+		if pats[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pats[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pats[0], segs[0]); !ok {
+			return false
+		}
+		pats, segs = pats[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"strings"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"src/*.go", "src/main.go", true},
+		{"src/*.go", "src/cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "src/cmd/main.go", true},
+		{"src/**", "src/cmd/main.go", true},
+		{"src/**", "src", true},
+		{"src/**/test", "src/test", true},
+		{"src/**/test", "src/a/b/test", true},
+		{"src/**/test", "src/a/b/test/x", false},
+		{"a/**/b/**/c", "a/x/b/y/z/c", true},
+		{"a/**/b/**/c", "a/x/c", false},
+		{"*/build", "tools/build", true},
+		{"*/build", "build", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+			if got != tt.want {
+				t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchFilterIgnored(t *testing.T) {
+	tests := []struct {
+		name   string
+		roots  []string
+		ignore []string
+		path   string
+		want   bool
+	}{
+		{"segment anywhere", []string{"/w"}, []string{"*.tmp"}, "/w/a/b/x.tmp", true},
+		{"segment directory covers below", []string{"/w"}, []string{"node_modules"}, "/w/web/node_modules/pkg/index.js", true},
+		{"segment no match", []string{"/w"}, []string{"*.tmp"}, "/w/a/x.go", false},
+		{"anchored at root", []string{"/w"}, []string{"/build"}, "/w/build/out", true},
+		{"anchored not nested", []string{"/w"}, []string{"/build"}, "/w/src/build/out", false},
+		{"slash pattern relative to root", []string{"/w"}, []string{"docs/*.md"}, "/w/docs/a.md", true},
+		{"slash pattern not nested", []string{"/w"}, []string{"docs/*.md"}, "/w/src/docs/a.md", false},
+		{"double star", []string{"/w"}, []string{".git/**"}, "/w/.git/objects/ab/cd", true},
+		{"double star in the middle", []string{"/w"}, []string{"src/**/gen"}, "/w/src/a/b/gen/x.go", true},
+		{"negated re-includes", []string{"/w"}, []string{"build", "!build/keep.txt"}, "/w/build/keep.txt", false},
+		{"negated leaves the rest", []string{"/w"}, []string{"build", "!build/keep.txt"}, "/w/build/other.txt", true},
+		{"negated segment", []string{"/w"}, []string{"*.log", "!important.log"}, "/w/a/important.log", false},
+		{"last pattern decides", []string{"/w"}, []string{"*.log", "!important.log", "a/**"}, "/w/a/important.log", true},
+		{"negated alone ignores nothing", []string{"/w"}, []string{"!x"}, "/w/y", false},
+		{"second root", []string{"/w", "/v"}, []string{"/build"}, "/v/build/out", true},
+		{"innermost root", []string{"/w", "/w/sub"}, []string{"/build"}, "/w/sub/build/out", true},
+		{"outer root still anchored", []string{"/w", "/w/sub"}, []string{"/build"}, "/w/build/out", true},
+		{"outside roots", []string{"/w"}, []string{"*"}, "/elsewhere/x", false},
+		{"root itself", []string{"/w"}, []string{"*"}, "/w", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newWatchFilter(tt.roots, tt.ignore, nil, nil)
+			if err != nil {
+				t.Fatalf("newWatchFilter: %v", err)
+			}
+			if got := f.ignored(tt.path); got != tt.want {
+				t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchFilterPrunes(t *testing.T) {
+	plain, err := newWatchFilter([]string{"/w"}, []string{"build"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plain.prunes("/w/build") {
+		t.Error("ignored directory not pruned")
+	}
+
+	negated, err := newWatchFilter([]string{"/w"}, []string{"build", "!build/keep.txt"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if negated.prunes("/w/build") {
+		t.Error("directory pruned despite a negated pattern re-including below it")
+	}
+}
+
+func TestWatchFilterAllows(t *testing.T) {
+	f, err := newWatchFilter([]string{"/w"}, []string{"*.tmp"}, []string{".go", "TOML"}, []string{eventModify})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path, kind string
+		want       bool
+	}{
+		{"/w/main.go", eventModify, true},
+		{"/w/config.toml", eventModify, true},
+		{"/w/main.go", eventCreate, false},
+		{"/w/notes.md", eventModify, false},
+		{"/w/main.go.tmp", eventModify, false},
+	}
+	for _, tt := range tests {
+		if got := f.allows(tt.path, tt.kind); got != tt.want {
+			t.Errorf("allows(%q, %q) = %v, want %v", tt.path, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestNewWatchFilterRejects(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		ignore, events []string
+	}{
+		{"malformed pattern", []string{"[a-"}, nil},
+		{"bare negation", []string{"!"}, nil},
+		{"unknown event", nil, []string{"touch"}},
+	} {
+		if _, err := newWatchFilter([]string{"/w"}, tt.ignore, nil, tt.events); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
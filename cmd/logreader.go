@@ -0,0 +1,311 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// followInterval is how often a followed log is checked for growth, truncation & rotation
+const followInterval = 250 * time.Millisecond
+
+// logFilter selects log lines; zero values select everything
+type logFilter struct {
+	since time.Time
+	grep  *regexp.Regexp
+}
+
+// logLine is a line of a daemon log together with the time it was written, when known
+type logLine struct {
+	text string
+	at   time.Time
+}
+
+// keep reports whether a line passes the filter
+func (f logFilter) keep(l logLine) bool {
+	if !f.since.IsZero() && (l.at.IsZero() || l.at.Before(f.since)) {
+		return false
+	}
+	return f.grep == nil || f.grep.MatchString(l.text)
+}
+
+// render highlights grep matches when colour is enabled
+func (f logFilter) render(text string) string {
+	if f.grep == nil || !colorEnabled {
+		return text
+	}
+	return f.grep.ReplaceAllStringFunc(text, func(m string) string { return chalk.Red.Color(m) })
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// lineTime reads a timestamp leading a line, as RFC 3339 or "2006-01-02 15:04:05"
+func lineTime(text string) (time.Time, bool) {
+	if field, _, _ := strings.Cut(text, " "); field != "" {
+		if t, err := time.Parse(time.RFC3339Nano, strings.Trim(field, "[]")); err == nil {
+			return t, true
+		}
+	}
+	if len(text) >= 19 {
+		if t, err := time.ParseInLocation(time.DateTime, text[:19], time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// timeline stamps lines lacking a timestamp of their own with that of the last stamped line.
+// Records of the json log format read as their text form
+type timeline struct {
+	last time.Time
+}
+
+func (tl *timeline) line(text string) logLine {
+	if strings.HasPrefix(text, "{") {
+		if r, ok := parseLogRecord(text); ok {
+			tl.last = r.Time
+			return logLine{text: r.text(), at: r.Time}
+		}
+	}
+	if t, ok := lineTime(text); ok {
+		tl.last = t
+	}
+	return logLine{text: text, at: tl.last}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// readLog returns the lines of path & its rotated segments passing the filter, at most the last n of them
+// when n > 0, together with the offset reached in path, from which a follow picks up
+func readLog(path string, n int, filter logFilter, tl *timeline) ([]logLine, int64, error) {
+	out, offset, err := readLogFile(path, n, filter, tl)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// segments, newest first, only fill what the live log lacks
+	segments := logSegments(path)
+	for i := len(segments) - 1; i >= 0 && (n <= 0 || len(out) < n); i-- {
+		rest := 0
+		if n > 0 {
+			rest = n - len(out)
+		}
+		older, _, err := readLogFile(segments[i], rest, filter, &timeline{})
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(older, out...)
+	}
+	return out, offset, nil
+}
+
+// readLogFile reads a single log or segment as readLog does
+func readLogFile(path string, n int, filter logFilter, tl *timeline) ([]logLine, int64, error) {
+	const op = "summon.read"
+
+	f, err := openSegment(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, horus.NewCategorizedHerror(op, "env_error", "opening log", err, map[string]any{"path": path})
+	}
+	defer f.Close()
+
+	var (
+		out    []logLine
+		offset int64
+	)
+	r := bufio.NewReader(f)
+	for {
+		text, err := r.ReadString('\n')
+		// a trailing partial line is left for follow to complete
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, horus.NewCategorizedHerror(op, "env_error", "reading log", err, map[string]any{"path": path})
+		}
+		offset += int64(len(text))
+		l := tl.line(strings.TrimRight(text, "\r\n"))
+		if !filter.keep(l) {
+			continue
+		}
+		out = append(out, l)
+		if n > 0 && len(out) > n {
+			out = out[1:]
+		}
+	}
+	return out, offset, nil
+}
+
+// followLog emits lines appended to path after offset until stop closes.
+// A file shorter than the offset was truncated & is read again from the start,
+// a different file under the same path was rotated in & is read from its start
+func followLog(path string, offset int64, filter logFilter, tl *timeline, emit func(logLine), stop <-chan struct{}) error {
+	const op = "summon.follow"
+
+	var (
+		f       *os.File
+		info    os.FileInfo
+		partial string
+	)
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	open := func(from int64) {
+		if f != nil {
+			f.Close()
+			f = nil
+		}
+		next, err := os.Open(path)
+		if err != nil {
+			return
+		}
+		if info, err = next.Stat(); err != nil {
+			next.Close()
+			return
+		}
+		if from > info.Size() {
+			from = 0
+		}
+		if _, err := next.Seek(from, io.SeekStart); err != nil {
+			next.Close()
+			return
+		}
+		f, offset, partial = next, from, ""
+	}
+	open(offset)
+
+	buf := make([]byte, 32*1024)
+	drain := func() error {
+		for f != nil {
+			n, err := f.Read(buf)
+			if n > 0 {
+				offset += int64(n)
+				lines := strings.Split(partial+string(buf[:n]), "\n")
+				partial = lines[len(lines)-1]
+				for _, text := range lines[:len(lines)-1] {
+					if l := tl.line(strings.TrimRight(text, "\r")); filter.keep(l) {
+						emit(l)
+					}
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return horus.NewCategorizedHerror(op, "env_error", "reading log", err, map[string]any{"path": path})
+			}
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		if err := drain(); err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(path)
+		switch {
+		case err != nil:
+			// rotated away & not yet recreated
+		case f == nil:
+			open(0)
+		case !os.SameFile(info, current):
+			// finish the rotated file before switching to its successor
+			if err := drain(); err != nil {
+				return err
+			}
+			open(0)
+		case current.Size() < offset:
+			open(0)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// stdoutIsTerminal reports whether output reaches a person rather than a pipe or file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// withPager hands what write produces to $PAGER, `less -R` by default, when stdout is a terminal;
+// otherwise write goes to stdout untouched
+func withPager(write func(io.Writer) error) error {
+	const op = "summon.pager"
+
+	if !stdoutIsTerminal() {
+		return write(os.Stdout)
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	c := exec.Command("sh", "-c", pager)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	in, err := c.StdinPipe()
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "connecting pager", err, map[string]any{"pager": pager})
+	}
+	if err := c.Start(); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "starting pager", err, map[string]any{"pager": pager})
+	}
+
+	// a pager quit early closes the pipe, which is not an error worth reporting
+	writeErr := write(in)
+	_ = in.Close()
+	if err := c.Wait(); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "running pager", err, map[string]any{"pager": pager})
+	}
+	if writeErr != nil && !errors.Is(writeErr, syscall.EPIPE) {
+		return writeErr
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,348 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// defaultLogMaxFiles is how many rotated segments are kept when log_max_files is not set
+const defaultLogMaxFiles = 5
+
+// segmentStamp suffixes rotated segments, e.g. helix.log.20250601-100000.000, so names sort chronologically
+const segmentStamp = "20060102-150405.000"
+
+// logRotation is the rotation & retention policy of a daemon log; a zero maxSize never rotates
+type logRotation struct {
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration // zero keeps segments regardless of age
+	compress bool
+}
+
+// logRotationOf merges the log keys of a daemon over those of ~/.lilith/lilith.toml
+func logRotationOf(meta *DaemonMeta) (logRotation, error) {
+	const op = "log.rotation"
+
+	settings, err := loadSettings()
+	if err != nil {
+		return logRotation{}, err
+	}
+
+	size, files, age := meta.LogMaxSize, meta.LogMaxFiles, meta.LogMaxAge
+	if size == "" {
+		size = settings.GetString("log_max_size")
+	}
+	if files == 0 && settings.IsSet("log_max_files") {
+		if files, err = strconv.Atoi(settings.GetString("log_max_files")); err != nil {
+			return logRotation{}, horus.NewCategorizedHerror(op, "config_error", "parsing log_max_files", err, map[string]any{"path": settingsPath()})
+		}
+	}
+	if files <= 0 {
+		files = defaultLogMaxFiles
+	}
+	if age == "" {
+		age = settings.GetString("log_max_age")
+	}
+
+	policy := logRotation{maxFiles: files, compress: meta.LogCompress || settings.GetBool("log_compress")}
+	if size != "" {
+		if policy.maxSize, err = parseSize(size); err != nil {
+			return logRotation{}, horus.NewCategorizedHerror(op, "config_error", "parsing log_max_size", err, map[string]any{"daemon": meta.Name})
+		}
+	}
+	if age != "" {
+		if policy.maxAge, err = parseAge(age); err != nil {
+			return logRotation{}, horus.NewCategorizedHerror(op, "config_error", "parsing log_max_age", err, map[string]any{"daemon": meta.Name})
+		}
+	}
+	return policy, nil
+}
+
+// parseSize reads a byte count with an optional unit, e.g. 512KB, 100MB or 1GB, in powers of 1024
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}}
+
+	num, factor := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(num, u.suffix) {
+			num, factor = strings.TrimSpace(strings.TrimSuffix(num, u.suffix)), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("size %q is not a positive number of bytes, KB, MB or GB", s)
+	}
+	return int64(n * float64(factor)), nil
+}
+
+// parseAge reads a duration, also accepting whole days, e.g. 7d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("age %q is not a positive number of days", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("age %q is not a positive duration", s)
+	}
+	return d, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// logOutput returns where a shim or chained rite writes the records of meta: stdout when it is a pipe
+// to the shim, otherwise the log file itself, rotated under the daemon's policy
+func logOutput(meta *DaemonMeta) (io.Writer, error) {
+	const op = "log.output"
+
+	info, err := os.Stdout.Stat()
+	if err != nil || !info.Mode().IsRegular() || meta.LogPath == "" {
+		return os.Stdout, nil
+	}
+	policy, err := logRotationOf(meta)
+	if err != nil {
+		return os.Stdout, horus.Wrap(err, op, "reading log policy")
+	}
+	if policy.maxSize == 0 {
+		return os.Stdout, nil
+	}
+	r, err := openRotatingLog(meta.LogPath, policy)
+	if err != nil {
+		return os.Stdout, err
+	}
+	return r, nil
+}
+
+// rotatingLog appends to a log, moving it aside as a segment before a write would exceed maxSize.
+// Every process writing the same log rotates it: the one holding the lock renames it,
+// the others notice the path now names another file & reopen it
+type rotatingLog struct {
+	path   string
+	policy logRotation
+	f      *os.File
+}
+
+func openRotatingLog(path string, policy logRotation) (*rotatingLog, error) {
+	r := &rotatingLog{path: path, policy: policy}
+	if err := r.reopen(); err != nil {
+		return nil, err
+	}
+	pruneLogSegments(path, policy)
+	return r, nil
+}
+
+func (r *rotatingLog) reopen() error {
+	const op = "log.open"
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "opening log", err, map[string]any{"path": r.path})
+	}
+	if r.f != nil {
+		_ = r.f.Close()
+	}
+	r.f = f
+	return nil
+}
+
+func (r *rotatingLog) Write(p []byte) (int, error) {
+	if err := r.rotateIfFull(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return r.f.Write(p)
+}
+
+// rotateIfFull follows a rotation done elsewhere, then rotates when next bytes would overflow the log;
+// a log holding nothing is never rotated, however long the write
+func (r *rotatingLog) rotateIfFull(next int64) error {
+	mine, err := r.f.Stat()
+	if err != nil {
+		return err
+	}
+	if current, err := os.Stat(r.path); err != nil || !os.SameFile(mine, current) {
+		if err := r.reopen(); err != nil {
+			return err
+		}
+		if mine, err = r.f.Stat(); err != nil {
+			return err
+		}
+	}
+	if mine.Size() == 0 || mine.Size()+next <= r.policy.maxSize {
+		return nil
+	}
+	return r.rotate()
+}
+
+func (r *rotatingLog) rotate() error {
+	const op = "log.rotate"
+
+	old := r.f
+	if err := syscall.Flock(int(old.Fd()), syscall.LOCK_EX); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "locking log", err, map[string]any{"path": r.path})
+	}
+	// another writer may have rotated while this one waited for the lock
+	mine, err := old.Stat()
+	current, statErr := os.Stat(r.path)
+	if err != nil || statErr != nil || !os.SameFile(mine, current) {
+		return r.reopenUnlocking(old)
+	}
+
+	segment := r.path + "." + time.Now().Format(segmentStamp)
+	if err := os.Rename(r.path, segment); err != nil {
+		_ = syscall.Flock(int(old.Fd()), syscall.LOCK_UN)
+		return horus.NewCategorizedHerror(op, "env_error", "rotating log", err, map[string]any{"path": r.path})
+	}
+	if err := r.reopenUnlocking(old); err != nil {
+		return err
+	}
+
+	if r.policy.compress {
+		if err := gzipFile(segment); err != nil {
+			return horus.Wrap(err, op, "compressing segment")
+		}
+	}
+	pruneLogSegments(r.path, r.policy)
+	return nil
+}
+
+// reopenUnlocking reopens the log; closing old releases its lock, which is otherwise released here
+func (r *rotatingLog) reopenUnlocking(old *os.File) error {
+	if err := r.reopen(); err != nil {
+		_ = syscall.Flock(int(old.Fd()), syscall.LOCK_UN)
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// logSegments lists the rotated segments of a log, oldest first, compressed or not
+func logSegments(path string) []string {
+	dir, base := filepath.Split(path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+
+	var out []string
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), base+".")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(segmentStamp, strings.TrimSuffix(stamp, ".gz")); err != nil {
+			continue
+		}
+		out = append(out, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// pruneLogSegments removes segments beyond maxFiles & those older than maxAge
+func pruneLogSegments(path string, policy logRotation) {
+	segments := logSegments(path)
+	for i, segment := range segments {
+		stale := len(segments)-i > policy.maxFiles
+		if !stale && policy.maxAge > 0 {
+			info, err := os.Stat(segment)
+			stale = err == nil && time.Since(info.ModTime()) > policy.maxAge
+		}
+		if stale {
+			_ = os.Remove(segment)
+		}
+	}
+}
+
+// gzipFile replaces path by path.gz
+func gzipFile(path string) error {
+	const op = "log.gzip"
+
+	in, err := os.Open(path)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "opening segment", err, map[string]any{"path": path})
+	}
+	defer in.Close()
+
+	// written aside first, so readers never meet a truncated archive
+	dir, base := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, "."+base+".gz-*")
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "creating archive", err, map[string]any{"path": path})
+	}
+	defer os.Remove(tmp.Name())
+	// segments stay as readable as the log they came from
+	_ = tmp.Chmod(0644)
+
+	zw := gzip.NewWriter(tmp)
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path+".gz")
+	}
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "writing archive", err, map[string]any{"path": path})
+	}
+	return os.Remove(path)
+}
+
+// openSegment reads a log or one of its segments, inflating compressed ones
+func openSegment(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil || !strings.HasSuffix(path, ".gz") {
+		return f, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, f}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
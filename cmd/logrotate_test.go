@@ -0,0 +1,321 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		invalid bool
+	}{
+		{in: "512", want: 512},
+		{in: "512B", want: 512},
+		{in: "1K", want: 1 << 10},
+		{in: "1KB", want: 1 << 10},
+		{in: "100MB", want: 100 << 20},
+		{in: "1.5M", want: 3 << 19},
+		{in: "1GB", want: 1 << 30},
+		{in: " 2 gb ", want: 2 << 30},
+		{in: "", invalid: true},
+		{in: "MB", invalid: true},
+		{in: "0", invalid: true},
+		{in: "-1MB", invalid: true},
+		{in: "ten MB", invalid: true},
+		{in: "1TB", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("parseSize(%q) = %d, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		invalid bool
+	}{
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "1d", want: 24 * time.Hour},
+		{in: "90m", want: 90 * time.Minute},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "", invalid: true},
+		{in: "d", invalid: true},
+		{in: "0d", invalid: true},
+		{in: "-1d", invalid: true},
+		{in: "1.5d", invalid: true},
+		{in: "7", invalid: true},
+		{in: "0s", invalid: true},
+		{in: "-5m", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseAge(tt.in)
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("parseAge(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseAge(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestRotatingLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		writes   []string
+		log      string   // left in the log
+		segments []string // contents of the segments, oldest first
+	}{
+		{
+			name:   "fits",
+			writes: []string{"1234", "5678"},
+			log:    "12345678",
+		},
+		{
+			name:     "overflow rotates before writing",
+			writes:   []string{"123456", "7890ab"},
+			log:      "7890ab",
+			segments: []string{"123456"},
+		},
+		{
+			name:   "empty log takes any write",
+			writes: []string{"a write longer than the limit"},
+			log:    "a write longer than the limit",
+		},
+		{
+			name:     "long write after rotation",
+			writes:   []string{"12345678", "a write longer than the limit"},
+			log:      "a write longer than the limit",
+			segments: []string{"12345678"},
+		},
+		{
+			name:     "compressed",
+			compress: true,
+			writes:   []string{"123456", "7890ab", "cdefgh"},
+			log:      "cdefgh",
+			segments: []string{"123456", "7890ab"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "helix.log")
+			r, err := openRotatingLog(path, logRotation{maxSize: 10, maxFiles: 5, compress: tt.compress})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.f.Close()
+			for _, w := range tt.writes {
+				if _, err := io.WriteString(r, w); err != nil {
+					t.Fatal(err)
+				}
+				// segments are named to the millisecond
+				time.Sleep(2 * time.Millisecond)
+			}
+
+			if got := readSegment(t, path); got != tt.log {
+				t.Errorf("log = %q, want %q", got, tt.log)
+			}
+			segments := logSegments(path)
+			var got []string
+			for _, segment := range segments {
+				if strings.HasSuffix(segment, ".gz") != tt.compress {
+					t.Errorf("segment %s, compressed = %v", filepath.Base(segment), tt.compress)
+				}
+				got = append(got, readSegment(t, segment))
+			}
+			if !slices.Equal(got, tt.segments) {
+				t.Errorf("segments = %q, want %q", got, tt.segments)
+			}
+		})
+	}
+}
+
+func TestRotatingLogFollowsOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "helix.log")
+	policy := logRotation{maxSize: 10, maxFiles: 5}
+	first, err := openRotatingLog(path, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.f.Close()
+	second, err := openRotatingLog(path, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.f.Close()
+
+	for _, step := range []struct {
+		log  *rotatingLog
+		text string
+	}{{first, "123456"}, {first, "7890ab"}, {second, "cd"}} {
+		if _, err := io.WriteString(step.log, step.text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the second writer reopens the log the first one rotated, rather than appending to the segment
+	if got := readSegment(t, path); got != "7890abcd" {
+		t.Errorf("log = %q, want %q", got, "7890abcd")
+	}
+	if segments := logSegments(path); len(segments) != 1 || readSegment(t, segments[0]) != "123456" {
+		t.Errorf("segments = %v, want one holding %q", segments, "123456")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestPruneLogSegments(t *testing.T) {
+	const hour = time.Hour
+	tests := []struct {
+		name   string
+		policy logRotation
+		ages   []time.Duration // of the segments, oldest first
+		kept   []int           // indexes of the segments left
+	}{
+		{
+			name:   "by count keeps the newest",
+			policy: logRotation{maxFiles: 2},
+			ages:   []time.Duration{4 * hour, 3 * hour, 2 * hour, hour},
+			kept:   []int{2, 3},
+		},
+		{
+			name:   "under count",
+			policy: logRotation{maxFiles: 5},
+			ages:   []time.Duration{2 * hour, hour},
+			kept:   []int{0, 1},
+		},
+		{
+			name:   "by age",
+			policy: logRotation{maxFiles: 5, maxAge: 90 * time.Minute},
+			ages:   []time.Duration{3 * hour, 2 * hour, hour, 0},
+			kept:   []int{2, 3},
+		},
+		{
+			name:   "count & age together",
+			policy: logRotation{maxFiles: 1, maxAge: 90 * time.Minute},
+			ages:   []time.Duration{3 * hour, hour, 0},
+			kept:   []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "helix.log")
+			base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+			var segments []string
+			for i, age := range tt.ages {
+				// alternate compressed & plain segments, which are pruned alike
+				segment := path + "." + base.Add(time.Duration(i)*time.Minute).Format(segmentStamp)
+				if i%2 == 1 {
+					segment += ".gz"
+				}
+				writeFile(t, segment, "")
+				mtime := time.Now().Add(-age)
+				if err := os.Chtimes(segment, mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+				segments = append(segments, segment)
+			}
+			// files that are not segments of this log are left alone, however old
+			others := []string{path, path + ".bak", filepath.Join(dir, "other.log."+base.Format(segmentStamp))}
+			for _, other := range others {
+				writeFile(t, other, "")
+				old := time.Now().Add(-48 * hour)
+				if err := os.Chtimes(other, old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pruneLogSegments(path, tt.policy)
+
+			var want []string
+			for _, i := range tt.kept {
+				want = append(want, segments[i])
+			}
+			if got := logSegments(path); !slices.Equal(got, want) {
+				t.Errorf("segments left = %v, want %v", got, want)
+			}
+			for _, other := range others {
+				if _, err := os.Stat(other); err != nil {
+					t.Errorf("%s removed", filepath.Base(other))
+				}
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// readSegment reads a log or segment, inflating compressed ones
+func readSegment(t *testing.T, path string) string {
+	t.Helper()
+	r, err := openSegment(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
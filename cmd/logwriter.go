@@ -0,0 +1,257 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// formats accepted by the `log_format` key
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var logFormats = []string{logFormatText, logFormatJSON}
+
+// streams tagging every log record; run records frame the output of a run
+const (
+	streamOut = "out"
+	streamErr = "err"
+	streamRun = "run"
+)
+
+// events of run records
+const (
+	runStart = "start"
+	runEnd   = "end"
+)
+
+// logTimeLayout is RFC 3339 at a fixed millisecond width, so text records line up
+const logTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// maxLogLine bounds how much output without a newline is held before it is written as a record
+const maxLogLine = 64 * 1024
+
+// logRecord is one line of a daemon log
+type logRecord struct {
+	Time     time.Time `json:"time"`
+	Stream   string    `json:"stream"`
+	Run      string    `json:"run,omitempty"`
+	Line     string    `json:"line,omitempty"`
+	Event    string    `json:"event,omitempty"` // start or end of a run record
+	Trigger  []string  `json:"trigger,omitempty"`
+	Upstream string    `json:"upstream,omitempty"`
+	ExitCode *int      `json:"exitCode,omitempty"`
+	Signal   string    `json:"signal,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+}
+
+// text renders a record as a line of the text format, e.g.
+// "2025-06-01T10:00:00.000+02:00 out built" or "2025-06-01T10:00:00.250+02:00 run end 250601-100000.000 exit 0 after 250ms"
+func (r logRecord) text() string {
+	stamp := r.Time.Format(logTimeLayout)
+	if r.Stream != streamRun {
+		return stamp + " " + r.Stream + " " + r.Line
+	}
+
+	out := []string{stamp, r.Stream, r.Event, r.Run}
+	switch {
+	case r.Event == runEnd:
+		out = append(out, r.Line, "after", r.Duration)
+	case r.Upstream != "":
+		out = append(out, "upstream", r.Upstream)
+	case len(r.Trigger) > 0:
+		out = append(out, "trigger", strings.Join(r.Trigger, ", "))
+	}
+	return strings.Join(out, " ")
+}
+
+// parseLogRecord recognizes a line written by a logWriter in either format.
+// Text records keep everything after the stream tag in Line
+func parseLogRecord(text string) (logRecord, bool) {
+	if strings.HasPrefix(text, "{") {
+		var r logRecord
+		if err := json.Unmarshal([]byte(text), &r); err != nil || r.Time.IsZero() || !validStream(r.Stream) {
+			return logRecord{}, false
+		}
+		return r, true
+	}
+
+	stamp, rest, _ := strings.Cut(text, " ")
+	stream, line, _ := strings.Cut(rest, " ")
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil || !validStream(stream) {
+		return logRecord{}, false
+	}
+	return logRecord{Time: t, Stream: stream, Line: line}, true
+}
+
+func validStream(stream string) bool {
+	return stream == streamOut || stream == streamErr || stream == streamRun
+}
+
+// validLogFormat accepts the formats of logFormats, empty meaning text
+func validLogFormat(format string) error {
+	const op = "log.format"
+
+	if format == "" {
+		return nil
+	}
+	for _, f := range logFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return horus.NewCategorizedHerror(
+		op, "config_error", "unknown log format", nil,
+		map[string]any{"format": format, "available": strings.Join(logFormats, ", ")},
+	)
+}
+
+func completeLogFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return logFormats, cobra.ShellCompDirectiveNoFileComp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// logWriter writes timestamped records to a daemon log, one whole line per write,
+// so records of concurrent streams & processes never tear
+type logWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+	relay  bool // lines already formatted as records pass untouched, see shim
+}
+
+func newLogWriter(w io.Writer, format string) *logWriter {
+	return &logWriter{w: w, format: format}
+}
+
+// write stamps a record lacking a time & appends it in the configured format
+func (lw *logWriter) write(r logRecord) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	line := r.text()
+	if lw.format == logFormatJSON {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		line = string(b)
+	}
+	return lw.raw(line)
+}
+
+func (lw *logWriter) raw(line string) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, err := io.WriteString(lw.w, line+"\n")
+	return err
+}
+
+// runStarted writes the header of a run, naming what triggered it
+func (lw *logWriter) runStarted(rec *RunRecord) error {
+	return lw.write(logRecord{
+		Time:     rec.StartedAt,
+		Stream:   streamRun,
+		Run:      rec.ID,
+		Event:    runStart,
+		Trigger:  rec.Trigger,
+		Upstream: rec.Upstream,
+	})
+}
+
+// runEnded writes the footer of a run, with how it ended & how long it took
+func (lw *logWriter) runEnded(rec *RunRecord) error {
+	code := rec.ExitCode
+	return lw.write(logRecord{
+		Time:     rec.FinishedAt,
+		Stream:   streamRun,
+		Run:      rec.ID,
+		Event:    runEnd,
+		Line:     rec.Summary(),
+		ExitCode: &code,
+		Signal:   rec.Signal,
+		Duration: rec.Duration,
+	})
+}
+
+// stream returns a writer turning every line written to it into a record of stream
+func (lw *logWriter) stream(name, run string) *streamWriter {
+	return &streamWriter{lw: lw, name: name, run: run}
+}
+
+// streamWriter splits output into lines, holding a partial line until it completes or Flush
+type streamWriter struct {
+	lw      *logWriter
+	name    string
+	run     string
+	partial []byte
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 && len(s.partial) < maxLogLine {
+			return len(p), nil
+		}
+		if i < 0 {
+			i = len(s.partial)
+		}
+		line := string(bytes.TrimRight(s.partial[:i], "\r"))
+		s.partial = s.partial[min(i+1, len(s.partial)):]
+		if err := s.emit(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes a trailing partial line
+func (s *streamWriter) Flush() error {
+	if len(s.partial) == 0 {
+		return nil
+	}
+	line := string(bytes.TrimRight(s.partial, "\r"))
+	s.partial = nil
+	return s.emit(line)
+}
+
+func (s *streamWriter) emit(line string) error {
+	if s.lw.relay {
+		if _, ok := parseLogRecord(line); ok {
+			return s.lw.raw(line)
+		}
+	}
+	return s.lw.write(logRecord{Stream: s.name, Run: s.run, Line: line})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
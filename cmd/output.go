@@ -0,0 +1,296 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+	"github.com/ttacon/chalk"
+	"gopkg.in/yaml.v3"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// output formats accepted by --output
+const (
+	outputTable = "table"
+	outputWide  = "wide"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var (
+	outputFormat = outputTable
+	reported     []DaemonView // views collected by report for structured formats
+	outcomes     int          // daemons reported on, whatever the format
+	failures     int          // of which reportFailure announced
+)
+
+// addOutputFlag registers --output on a command that reports daemons
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().VarP((*outputValue)(&outputFormat), "output", "o", "Output format: table, wide, json, yaml")
+	horus.CheckErr(
+		cmd.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return []string{outputTable, outputWide, outputJSON, outputYAML}, cobra.ShellCompDirectiveNoFileComp
+		}),
+		horus.WithOp("output.init"),
+		horus.WithMessage("registering output completion"),
+	)
+}
+
+// outputValue rejects unknown formats while flags are parsed, before any daemon is touched
+type outputValue string
+
+func (o *outputValue) String() string { return string(*o) }
+func (o *outputValue) Type() string   { return "format" }
+
+func (o *outputValue) Set(format string) error {
+	switch format {
+	case outputTable, outputWide, outputJSON, outputYAML:
+		*o = outputValue(format)
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected table, wide, json or yaml", format)
+}
+
+// structuredOutput reports whether output is meant for machines rather than people
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// DaemonView is the rendered form of a daemon: its metadata plus state derived when rendering
+type DaemonView struct {
+	*DaemonMeta
+	Status string `json:"status"`
+	Exit   string `json:"exit,omitempty"`   // how the watcher last exited, for dead daemons
+	Result string `json:"result,omitempty"` // what the lifecycle command did, e.g. "frozen"
+
+	Environ []string `json:"environ,omitempty"` // shadows the recorded environment, which may hold secrets
+}
+
+// daemon statuses, as shown by tally
+const (
+	statusAlive  = "alive"
+	statusFrozen = "frozen"
+	statusLimbo  = "limbo"
+	statusDead   = "dead"
+)
+
+// viewOf derives the status of a daemon via `ps`, telling T=stopped processes apart by the frozen mark.
+// A PID another process took since is dead, whatever state ps reports for it
+func viewOf(meta *DaemonMeta) DaemonView {
+	view := DaemonView{DaemonMeta: meta, Status: statusDead}
+	state := ""
+	if isDaemonActive(meta) {
+		if stateOut, err := exec.Command("ps", "-o", "state=", "-p", strconv.Itoa(meta.PID)).Output(); err == nil {
+			state = strings.TrimSpace(string(stateOut))
+		}
+	}
+	switch {
+	case state == "", strings.HasPrefix(state, "Z"):
+		if rec := lastExit(meta); rec != nil {
+			view.Exit = rec.Summary()
+		}
+	case strings.HasPrefix(state, "T") && meta.Frozen:
+		view.Status = statusFrozen
+	case strings.HasPrefix(state, "T"):
+		view.Status = statusLimbo
+	default:
+		view.Status = statusAlive
+	}
+	return view
+}
+
+// statusCell renders the status column, coloured when the terminal allows
+func (v DaemonView) statusCell() string {
+	text := v.Status
+	if v.Exit != "" {
+		text += " (" + v.Exit + ")"
+	}
+	switch v.Status {
+	case statusAlive:
+		return paint(chalk.Green, text)
+	case statusFrozen:
+		return paint(chalk.Cyan, text)
+	case statusLimbo:
+		return paint(chalk.Yellow, text)
+	default:
+		return paint(chalk.Red, text)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// report announces the outcome of a lifecycle command on one daemon:
+// people get message right away, machines get the daemon view once flushReports runs
+func report(meta *DaemonMeta, result, message string) {
+	reportAs(paint(chalk.Green, "OK:"), meta, result, message)
+}
+
+// reportAs is report with a mark other than OK, e.g. for skipped or failed daemons
+func reportAs(mark string, meta *DaemonMeta, result, message string) {
+	outcomes++
+	if structuredOutput() {
+		view := viewOf(meta)
+		view.Result = result
+		reported = append(reported, view)
+		return
+	}
+	fmt.Printf("%s %s\n", mark, message)
+}
+
+// reportFailure announces a lifecycle command failing on one daemon, leaving the others to proceed;
+// finishReports then fails the command
+func reportFailure(meta *DaemonMeta, err error, message string) {
+	failures++
+	reportAs(paint(chalk.Red, "FAILED:"), meta, "failed: "+err.Error(), fmt.Sprintf("%s: %v", message, err))
+}
+
+// flushReports encodes the views collected by report
+func flushReports() {
+	if structuredOutput() {
+		renderViews(reported)
+	}
+}
+
+// finishReports flushes the collected views, then exits non-zero if any daemon failed.
+// Commands call it last rather than deferring it, as horus.CheckErr exits without running deferred calls
+func finishReports(op string) {
+	flushReports()
+	if failures > 0 {
+		horus.CheckErr(horus.NewCategorizedHerror(op, "runtime_error", fmt.Sprintf("%d of %d daemons failed", failures, outcomes), nil, nil))
+	}
+}
+
+// renderViews writes daemons to stdout in the selected format
+func renderViews(views []DaemonView) {
+	const op = "output.render"
+
+	if views == nil {
+		views = []DaemonView{}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		horus.CheckErr(enc.Encode(views), horus.WithOp(op), horus.WithMessage("encoding json"))
+
+	case outputYAML:
+		out, err := toYAML(views)
+		horus.CheckErr(err, horus.WithOp(op), horus.WithMessage("encoding yaml"))
+		fmt.Print(string(out))
+
+	case outputWide:
+		fmt.Printf(
+			"%-20s %-15s %-6s %-20s %-18s %-10s %-10s %-30s %s\n",
+			"NAME", "GROUP", "PID", "INVOKED", "STATUS", "BACKEND", "RESTART", "WATCH", "SCRIPT",
+		)
+		for _, v := range views {
+			fmt.Printf(
+				"%-20s %-15s %-6d %-20s %s %-10s %-10s %-30s %s\n",
+				v.Name, v.Group, v.PID, v.InvokedAt.Format("2006-01-02 15:04:05"), padCell(v.statusCell(), 18),
+				orDash(v.Backend), orDash(v.Restart), strings.Join(v.WatchPaths, ","), scriptLabel(v.DaemonMeta),
+			)
+		}
+
+	default:
+		fmt.Printf(
+			"%-20s %-15s %-6s %-20s %s\n",
+			"NAME", "GROUP", "PID", "INVOKED", "STATUS",
+		)
+		for _, v := range views {
+			fmt.Printf(
+				"%-20s %-15s %-6d %-20s %s\n",
+				v.Name, v.Group, v.PID, v.InvokedAt.Format("2006-01-02 15:04:05"), v.statusCell(),
+			)
+		}
+	}
+}
+
+// toYAML re-encodes the JSON form as block YAML, so both formats share keys & field order
+func toYAML(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// blockStyle drops the flow & quoting styles yaml keeps from JSON input
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// colorEnabled reports whether stdout is a terminal & NO_COLOR is unset
+var colorEnabled = func() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return stdoutIsTerminal()
+}()
+
+// paint colours s when colour is enabled
+func paint(c chalk.Color, s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return c.Color(s)
+}
+
+// padCell pads a possibly coloured cell to width visible characters
+func padCell(s string, width int) string {
+	visible := len(s)
+	if colorEnabled {
+		// chalk wraps text in one opening & one closing escape of five bytes each
+		visible -= 10
+	}
+	if visible >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-visible)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/ttacon/chalk"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// how a watched path relates to one of another daemon
+const (
+	overlapSame   = "same"
+	overlapInside = "inside"
+	overlapHolds  = "holds"
+)
+
+// watchOverlap is a path of the daemon being invoked that another live daemon already covers
+type watchOverlap struct {
+	Daemon   *DaemonMeta
+	Path     string // as given to the daemon being invoked
+	Other    string // as given to Daemon
+	Relation string
+}
+
+func (o watchOverlap) String() string {
+	switch {
+	case o.Relation == overlapSame && o.Path == o.Other:
+		return fmt.Sprintf("both watch %s", o.Path)
+	case o.Relation == overlapSame:
+		return fmt.Sprintf("%s & %s are the same path", o.Path, o.Other)
+	case o.Relation == overlapInside:
+		return fmt.Sprintf("%s lies inside %s", o.Path, o.Other)
+	default:
+		return fmt.Sprintf("%s holds %s", o.Path, o.Other)
+	}
+}
+
+// canonicalPath resolves symlinks of an absolute, cleaned path; paths yet to exist are only cleaned
+func canonicalPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// overlapOf returns the first pair of paths shared or nested between two daemons, compared canonically
+func overlapOf(meta, other *DaemonMeta) (watchOverlap, bool) {
+	for _, p := range meta.WatchPaths {
+		cp := canonicalPath(p)
+		for _, q := range other.WatchPaths {
+			cq := canonicalPath(q)
+			o := watchOverlap{Daemon: other, Path: p, Other: q}
+			switch {
+			case cp == cq:
+				o.Relation = overlapSame
+			case within(cq, cp):
+				o.Relation = overlapInside
+			case within(cp, cq):
+				o.Relation = overlapHolds
+			default:
+				continue
+			}
+			return o, true
+		}
+	}
+	return watchOverlap{}, false
+}
+
+// watchOverlaps lists the live daemons, other than meta itself, whose paths overlap meta's,
+// as both would run their scripts for the same change
+func watchOverlaps(meta *DaemonMeta) []watchOverlap {
+	var out []watchOverlap
+	for _, path := range mustListDaemonMetaFiles() {
+		existing := mustLoadMeta(path)
+		if existing.Name == meta.Name || !isDaemonActive(existing) {
+			continue
+		}
+		if o, ok := overlapOf(meta, existing); ok {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// refuseOverlaps returns the first live daemon overlapping meta's paths with an error,
+// unless meta allows overlap, in which case every overlap is only warned about
+func refuseOverlaps(meta *DaemonMeta) (*DaemonMeta, error) {
+	for _, o := range watchOverlaps(meta) {
+		if !meta.AllowOverlap {
+			return o.Daemon, overlapError(o)
+		}
+		warnOverlap(meta, o)
+	}
+	return nil, nil
+}
+
+// overlapError refuses an overlap the daemon did not allow
+func overlapError(o watchOverlap) error {
+	return horus.NewCategorizedHerror(
+		"daemon.overlap", "spawn_error",
+		fmt.Sprintf("daemon %q already watches: %s; pass --allow-overlap or set allow_overlap to invoke anyway", o.Daemon.Name, o),
+		nil, map[string]any{"watch": o.Path, "daemon": o.Daemon.Name, "overlap": o.Relation},
+	)
+}
+
+// warnOverlap notes an allowed overlap on stderr, leaving stdout to reports
+func warnOverlap(meta *DaemonMeta, o watchOverlap) {
+	fmt.Fprintf(os.Stderr, "%s daemon %q overlaps daemon %q: %s\n", paint(chalk.Yellow, "WARNING:"), meta.Name, o.Daemon.Name, o)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,116 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestOverlapOf(t *testing.T) {
+	// the temporary directory may itself lie behind a symlink, e.g. /var on macOS
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"src/sub", "src2", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{"link": "src", "nested": "src/sub", "chain": "link"} {
+		if err := os.Symlink(filepath.Join(root, target), filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(paths ...string) []string {
+		var out []string
+		for _, p := range paths {
+			out = append(out, filepath.Join(root, p))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		other    []string
+		relation string // empty for no overlap
+		path     string // of paths, reported as overlapping
+	}{
+		{"same", at("src"), at("src"), overlapSame, "src"},
+		{"same once cleaned", []string{root + "/./src/"}, at("src"), overlapSame, ""},
+		{"symlink to the same", at("link"), at("src"), overlapSame, "link"},
+		{"symlink chain", at("chain"), at("src"), overlapSame, "chain"},
+		{"inside", at("src/sub"), at("src"), overlapInside, "src/sub"},
+		{"inside through a symlink", at("link/sub"), at("src"), overlapInside, "link/sub"},
+		{"symlink into a watched tree", at("nested"), at("link"), overlapInside, "nested"},
+		{"holds", at("src"), at("src/sub"), overlapHolds, "src"},
+		{"holds through a symlink", at("link"), at("nested"), overlapHolds, "link"},
+		{"sibling sharing a prefix", at("src"), at("src2"), "", ""},
+		{"disjoint", at("docs"), at("src", "src2"), "", ""},
+		{"path yet to exist", at("src/new/file"), at("src"), overlapInside, "src/new/file"},
+		{"first overlapping path", at("docs", "src2"), at("src", "src2"), overlapSame, "src2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := &DaemonMeta{Name: "helix", WatchPaths: tt.paths}
+			other := &DaemonMeta{Name: "language", WatchPaths: tt.other}
+			o, ok := overlapOf(meta, other)
+			if tt.relation == "" {
+				if ok {
+					t.Fatalf("overlapOf = %s %s, want none", o.Relation, o)
+				}
+				return
+			}
+			if !ok || o.Relation != tt.relation || o.Daemon != other {
+				t.Fatalf("overlapOf = %q, %v, want %q", o.Relation, ok, tt.relation)
+			}
+			if tt.path != "" && o.Path != filepath.Join(root, tt.path) {
+				t.Errorf("overlapping path = %s, want %s", o.Path, filepath.Join(root, tt.path))
+			}
+		})
+	}
+}
+
+func TestWatchOverlapString(t *testing.T) {
+	tests := []struct {
+		overlap watchOverlap
+		want    string
+	}{
+		{watchOverlap{Path: "/w", Other: "/w", Relation: overlapSame}, "both watch /w"},
+		{watchOverlap{Path: "/l", Other: "/w", Relation: overlapSame}, "/l & /w are the same path"},
+		{watchOverlap{Path: "/w/src", Other: "/w", Relation: overlapInside}, "/w/src lies inside /w"},
+		{watchOverlap{Path: "/w", Other: "/w/src", Relation: overlapHolds}, "/w holds /w/src"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.overlap.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,48 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// procStart reads when a process started, in clock ticks since boot, from field 22 of /proc/<pid>/stat
+func procStart(pid int) (string, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", err
+	}
+	// the command name may hold spaces & parentheses, so fields are counted after its closing one
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return "", fmt.Errorf("malformed stat of PID %d", pid)
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 {
+		return "", fmt.Errorf("malformed stat of PID %d", pid)
+	}
+	return fields[19], nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -19,11 +19,11 @@ package cmd
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"syscall"
@@ -135,13 +135,53 @@ func formatExample(app string, usages ...[]string) string {
 
 // DaemonMeta holds persistent info about process
 type DaemonMeta struct {
-	Name       string    `json:"name"`
-	Group      string    `json:"group"`
-	WatchDir   string    `json:"watchDir"`
-	ScriptPath string    `json:"scriptPath"`
-	LogPath    string    `json:"logPath"`
-	PID        int       `json:"pid"`
-	InvokedAt  time.Time `json:"invokedAt"`
+	Name         string            `json:"name"`
+	Group        string            `json:"group"`
+	Workflow     string            `json:"workflow,omitempty"`  // workflow invoked, empty for ad hoc daemons
+	DependsOn    []string          `json:"dependsOn,omitempty"` // workflows started before this one
+	OnSuccess    []string          `json:"onSuccess,omitempty"` // workflows run after a successful run
+	OnFailure    []string          `json:"onFailure,omitempty"` // workflows run after a failed run
+	WatchPaths   []string          `json:"watchPaths"`
+	WatchDir     string            `json:"watchDir,omitempty"` // single path of older metadata, moved into WatchPaths on load
+	ScriptPath   string            `json:"scriptPath,omitempty"`
+	Run          string            `json:"run,omitempty"`         // inline body, instead of ScriptPath
+	Interpreter  string            `json:"interpreter,omitempty"` // bash when empty, see interpreters
+	Args         []string          `json:"args,omitempty"`        // passed after the script
+	Cwd          string            `json:"cwd,omitempty"`         // working directory of watcher & script
+	Env          map[string]string `json:"env,omitempty"`         // laid over env_file & Environ
+	EnvFile      string            `json:"envFile,omitempty"`
+	Environ      []string          `json:"environ,omitempty"`     // environment lilith was invoked with, the base of every spawn
+	ChangedFile  bool              `json:"changedFile,omitempty"` // also hand changed paths to scripts as a file
+	Backend      string            `json:"backend"`
+	Debounce     string            `json:"debounce,omitempty"`     // quiet period before a run, backend default when empty
+	Ignore       []string          `json:"ignore,omitempty"`       // path patterns never triggering a run
+	Extensions   []string          `json:"extensions,omitempty"`   // only these extensions trigger a run
+	Events       []string          `json:"events,omitempty"`       // only these kinds of change trigger a run
+	AllowOverlap bool              `json:"allowOverlap,omitempty"` // started despite daemons watching overlapping paths
+	StopTimeout  string            `json:"stopTimeout,omitempty"`
+	LogPath      string            `json:"logPath"`
+	LogFormat    string            `json:"logFormat,omitempty"`  // text when empty, see logFormats
+	LogMaxSize   string            `json:"logMaxSize,omitempty"` // rotation & retention, over ~/.lilith/lilith.toml
+	LogMaxFiles  int               `json:"logMaxFiles,omitempty"`
+	LogMaxAge    string            `json:"logMaxAge,omitempty"`
+	LogCompress  bool              `json:"logCompress,omitempty"`
+	KeepLogs     bool              `json:"keepLogs,omitempty"` // slay archives rather than deletes, see archive
+	PID          int               `json:"pid"`
+	PGID         int               `json:"pgid"`
+	ShimStart    string            `json:"shimStart,omitempty"` // tells the shim apart from a later process reusing its PID
+	InvokedAt    time.Time         `json:"invokedAt"`
+	Frozen       bool              `json:"frozen"`
+	FrozenAt     time.Time         `json:"frozenAt"`
+	Stopping     bool              `json:"stopping,omitempty"`
+
+	// supervision, see vigil
+	Restart       string      `json:"restart,omitempty"`
+	MaxRestarts   int         `json:"maxRestarts,omitempty"`
+	RestartWindow string      `json:"restartWindow,omitempty"`
+	RestartCount  int         `json:"restartCount"`
+	Restarts      []time.Time `json:"restarts,omitempty"` // restarts inside the current window
+	LastExit      string      `json:"lastExit,omitempty"`
+	LastExitAt    time.Time   `json:"lastExitAt"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -159,12 +199,58 @@ var GetDaemonDir = func() string {
 
 // saveMeta writes meta to ~/.lilith/daemon/<name>.json
 func saveMeta(meta *DaemonMeta) error {
-	const op = "daemon.saveMeta"
+	unlock, err := lockMeta()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeMeta(meta)
+}
+
+// updateMeta re-reads the metadata of a daemon & saves it when change returns true, all under the lock,
+// so a decision taken on a stale copy never overwrites what another process recorded since.
+// Metadata removed meanwhile is reported as os.ErrNotExist
+func updateMeta(name string, change func(*DaemonMeta) bool) (*DaemonMeta, error) {
+	unlock, err := lockMeta()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	meta, err := loadMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	if !change(meta) {
+		return meta, nil
+	}
+	return meta, writeMeta(meta)
+}
+
+// lockMeta serializes metadata writes across lilith processes with a lock on the daemon directory,
+// which outlives the renames replacing each file; the returned function releases it
+func lockMeta() (func(), error) {
+	const op = "daemon.lockMeta"
 
 	dir := GetDaemonDir()
 	if err := domovoi.CreateDir(dir, false); err != nil {
-		return horus.Wrap(err, op, "creating daemon directory")
+		return nil, horus.Wrap(err, op, "creating daemon directory")
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "opening daemon directory", err, map[string]any{"dir": dir})
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, horus.NewCategorizedHerror(op, "env_error", "locking daemon directory", err, map[string]any{"dir": dir})
 	}
+	// closing the directory releases the lock
+	return func() { _ = f.Close() }, nil
+}
+
+// writeMeta replaces the metadata file of meta; callers hold the lock
+func writeMeta(meta *DaemonMeta) error {
+	const op = "daemon.saveMeta"
 
 	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
@@ -174,10 +260,19 @@ func saveMeta(meta *DaemonMeta) error {
 		)
 	}
 
-	path := filepath.Join(dir, meta.Name+".json")
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// write aside & rename so concurrent readers never see a torn file
+	path := filepath.Join(GetDaemonDir(), meta.Name+".json")
+	tmp := path + ".tmp"
+	// private, as it holds the environment of the invoking shell
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
 		return horus.NewCategorizedHerror(
 			op, "env_error", "writing metadata file", err,
+			map[string]any{"path": tmp},
+		)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return horus.NewCategorizedHerror(
+			op, "env_error", "replacing metadata file", err,
 			map[string]any{"path": path},
 		)
 	}
@@ -205,11 +300,67 @@ func loadMeta(name string) (*DaemonMeta, error) {
 			map[string]any{"path": path, "name": name},
 		)
 	}
+	if len(m.WatchPaths) == 0 && m.WatchDir != "" {
+		m.WatchPaths, m.WatchDir = []string{m.WatchDir}, ""
+	}
 
 	return &m, nil
 }
 
-// spawnWatcher starts watchexec, redirects logs, returns its PID
+// appendJSONLine appends v to path as a single JSON line, creating parent directories
+func appendJSONLine(path string, v any) error {
+	const op = "daemon.appendJSONLine"
+
+	if err := domovoi.CreateDir(filepath.Dir(path), false); err != nil {
+		return horus.Wrap(err, op, "creating directory")
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "encode_error", "marshaling record", err, map[string]any{"path": path})
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "opening file", err, map[string]any{"path": path})
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return horus.NewCategorizedHerror(op, "env_error", "writing file", err, map[string]any{"path": path})
+	}
+	return nil
+}
+
+// readJSONLines decodes every line of path, oldest first; a missing file yields no records
+func readJSONLines[T any](path string) ([]T, error) {
+	const op = "daemon.readJSONLines"
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "opening file", err, map[string]any{"path": path})
+	}
+	defer f.Close()
+
+	var out []T
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var rec T
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			// tolerate a torn final line
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+// spawnWatcher starts the configured watcher backend under a shim in its own session, redirects logs,
+// records PID & PGID on meta & returns the PID of the shim
 func spawnWatcher(meta *DaemonMeta) (int, error) {
 	const op = "daemon.spawnWatcher"
 	logDir := filepath.Dir(meta.LogPath)
@@ -218,11 +369,21 @@ func spawnWatcher(meta *DaemonMeta) (int, error) {
 		return 0, horus.Wrap(err, op, "creating log directory")
 	}
 
-	cmd := exec.Command("watchexec",
-		"--watch", meta.WatchDir,
-		"--",
-		"bash", meta.ScriptPath,
-	)
+	watcher, err := lookupWatcher(meta.Backend)
+	if err != nil {
+		return 0, horus.Wrap(err, op, "selecting watcher backend")
+	}
+	backend, err := watcher.Command(meta)
+	if err != nil {
+		return 0, horus.Wrap(err, op, "building watcher command")
+	}
+	cmd, err := selfCommand(append([]string{"shim", "--name", meta.Name, "--", backend.Path}, backend.Args[1:]...)...)
+	if err != nil {
+		return 0, horus.Wrap(err, op, "building shim command")
+	}
+	if err := applyExecContext(cmd, meta); err != nil {
+		return 0, horus.Wrap(err, op, "preparing execution context")
+	}
 
 	f, err := os.OpenFile(meta.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
@@ -233,15 +394,21 @@ func spawnWatcher(meta *DaemonMeta) (int, error) {
 	}
 	cmd.Stdout = f
 	cmd.Stderr = f
+	// new session: the watcher leads its own process group, detached from the terminal
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 
 	if err := cmd.Start(); err != nil {
 		_ = f.Close()
 		return 0, horus.NewCategorizedHerror(
 			op, "spawn_error", "starting watcher process", err,
-			map[string]any{"watch": meta.WatchDir, "script": meta.ScriptPath},
+			map[string]any{"watch": strings.Join(meta.WatchPaths, ", "), "script": scriptLabel(meta), "backend": meta.Backend},
 		)
 	}
 	pid := cmd.Process.Pid
+	meta.PID = pid
+	meta.PGID = pid
+	meta.ShimStart, _ = procStart(pid)
+	_ = f.Close()
 
 	if err := cmd.Process.Release(); err != nil {
 		return pid, horus.Wrap(err, op, "releasing process handle")
@@ -250,13 +417,15 @@ func spawnWatcher(meta *DaemonMeta) (int, error) {
 	return pid, nil
 }
 
-// BindFlag copies a Viper value into a flag variable if the flag was not set
+// BindFlag copies a Viper value into a flag variable if the flag was not set.
+// Dashed flags read their snake_case TOML key, e.g. --stop-timeout from stop_timeout
 func BindFlag(cmd *cobra.Command, flagName string, dest *string, cfg *viper.Viper) {
 	const op = "cli.bindFlag"
+	key := strings.ReplaceAll(flagName, "-", "_")
 
 	// Only override if flag not manually set and config has value
-	if !cmd.Flags().Changed(flagName) && cfg.IsSet(flagName) {
-		*dest = cfg.GetString(flagName)
+	if !cmd.Flags().Changed(flagName) && cfg.IsSet(key) {
+		*dest = cfg.GetString(key)
 
 		if err := cmd.Flags().Set(flagName, *dest); err != nil {
 			horus.CheckErr(horus.NewCategorizedHerror(
@@ -273,6 +442,16 @@ func BindFlag(cmd *cobra.Command, flagName string, dest *string, cfg *viper.Vipe
 	}
 }
 
+// BindSliceFlag is BindFlag for list keys such as ignore; the flag is left untouched,
+// as setting a slice flag appends to it
+func BindSliceFlag(cmd *cobra.Command, flagName string, dest *[]string, cfg *viper.Viper) {
+	key := strings.ReplaceAll(flagName, "-", "_")
+
+	if !cmd.Flags().Changed(flagName) && cfg.IsSet(key) {
+		*dest = configList(cfg, key)
+	}
+}
+
 func mustExpand(val, label string) string {
 	const op = "expand.path"
 	expanded, err := expandPath(val)
@@ -280,6 +459,27 @@ func mustExpand(val, label string) string {
 	return expanded
 }
 
+// resolvePath expands p & anchors it to the working directory, so it survives rekindle & --save
+func resolvePath(p string) (string, error) {
+	expanded, err := expandPath(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(expanded)
+}
+
+// resolveIn resolves p as resolvePath does, relative to dir rather than lilith's own cwd when dir is set
+func resolveIn(dir, p string) (string, error) {
+	expanded, err := expandPath(p)
+	if err != nil {
+		return "", err
+	}
+	if dir != "" && !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(dir, expanded)
+	}
+	return filepath.Abs(expanded)
+}
+
 // expandPath replaces a leading "~" with $HOME (via domovoi.FindHome) and then does os.ExpandEnv.
 func expandPath(p string) (string, error) {
 	prefix := "~" + string(filepath.Separator)
@@ -340,7 +540,7 @@ func completeDaemonNames(cmd *cobra.Command, args []string, toComplete string) (
 
 	var out []string
 	for _, fi := range fis {
-		if fi.IsDir() {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
 			continue
 		}
 		name := strings.TrimSuffix(fi.Name(), filepath.Ext(fi.Name()))
@@ -359,10 +559,20 @@ func isDaemonActive(meta *DaemonMeta) bool {
 	if err != nil {
 		return false
 	}
-	if err := proc.Signal(syscall.Signal(0)); err != nil {
-		return errors.Is(err, syscall.EPERM)
+	if err := proc.Signal(syscall.Signal(0)); err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	return !pidReused(meta)
+}
+
+// pidReused reports whether the PID of meta now names a process other than the shim spawned for it.
+// Metadata without a start time, written earlier or where start times are unknown, trusts the PID
+func pidReused(meta *DaemonMeta) bool {
+	if meta.ShimStart == "" || meta.PID <= 0 {
+		return false
 	}
-	return true
+	start, err := procStart(meta.PID)
+	return err == nil && start != meta.ShimStart
 }
 
 func mustListDaemonMetaFiles() []string {
@@ -400,6 +610,10 @@ func completeWorkflowGroups(cmd *cobra.Command, args []string, toComplete string
 	return availableGroups(), cobra.ShellCompDirectiveDefault
 }
 
+func completeBackends(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return backendNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
 func availableGroups() []string {
 	dir := GetDaemonDir()
 	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
@@ -431,33 +645,38 @@ func availableGroups() []string {
 	return result
 }
 
+// mustLoadMeta reads a metadata file listed in the daemon directory through loadMeta, exiting on failure
 func mustLoadMeta(path string) *DaemonMeta {
-	data, err := os.ReadFile(path)
+	meta, err := loadMeta(nameFrom(path))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading metadata from %s: %v\n", path, err)
-		os.Exit(1)
-	}
-
-	var meta DaemonMeta
-	if err := json.Unmarshal(data, &meta); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing JSON in %s: %v\n", path, err)
+		fmt.Fprintf(os.Stderr, "Error loading metadata from %s: %v\n", path, err)
 		os.Exit(1)
 	}
-
-	return &meta
+	return meta
 }
 
-func sendSignal(pid int, sig syscall.Signal) error {
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		return fmt.Errorf("could not find process %d: %w", pid, err)
+// signalDaemon delivers sig to the daemon's whole process group, reaching the script & its children.
+// Metadata written before groups were recorded falls back to the PID, group-signaled only when it leads one
+func signalDaemon(meta *DaemonMeta, sig syscall.Signal) error {
+	// the group died with the shim if another process took its PID
+	if pidReused(meta) {
+		return syscall.ESRCH
+	}
+	pgid := meta.PGID
+	if pgid <= 0 {
+		if leader, err := syscall.Getpgid(meta.PID); err == nil && leader == meta.PID {
+			pgid = leader
+		}
+	}
+	if pgid > 0 {
+		return syscall.Kill(-pgid, sig)
 	}
-	return proc.Signal(sig)
+	return syscall.Kill(meta.PID, sig)
 }
 
-func mustSpawnWatcher(meta DaemonMeta) int {
+func mustSpawnWatcher(meta *DaemonMeta) int {
 	const op = "lilith.mustSpawnWatcher"
-	pid, err := spawnWatcher(&meta)
+	pid, err := spawnWatcher(meta)
 	horus.CheckErr(err, horus.WithOp(op), horus.WithMessage(fmt.Sprintf("spawning %q", meta.Name)))
 	return pid
 }
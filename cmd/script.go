@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/DanielRivasMD/horus"
+	"github.com/spf13/cobra"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// interpreters accepted by the `interpreter` key; exec runs the script itself with args
+const (
+	interpreterBash   = "bash"
+	interpreterSh     = "sh"
+	interpreterZsh    = "zsh"
+	interpreterPython = "python3"
+	interpreterExec   = "exec"
+)
+
+var interpreters = []string{interpreterBash, interpreterSh, interpreterZsh, interpreterPython, interpreterExec}
+
+// defaultInterpreter runs scripts when the workflow names none
+const defaultInterpreter = interpreterBash
+
+// validScript checks that a workflow names exactly one of script & run, with an interpreter able to run it
+func validScript(wf Workflow) error {
+	const op = "script.validate"
+
+	switch {
+	case wf.Script == "" && wf.Run == "":
+		return horus.NewCategorizedHerror(op, "config_error", "either script or run is required", nil, nil)
+	case wf.Script != "" && wf.Run != "":
+		return horus.NewCategorizedHerror(op, "config_error", "script & run are mutually exclusive", nil, map[string]any{"script": wf.Script})
+	}
+
+	interpreter := wf.Interpreter
+	if interpreter == "" {
+		interpreter = defaultInterpreter
+	}
+	valid := false
+	for _, i := range interpreters {
+		valid = valid || i == interpreter
+	}
+	if !valid {
+		return horus.NewCategorizedHerror(
+			op, "config_error", "unknown interpreter", nil,
+			map[string]any{"interpreter": interpreter, "available": strings.Join(interpreters, ", ")},
+		)
+	}
+	if interpreter == interpreterExec && wf.Run != "" {
+		return horus.NewCategorizedHerror(op, "config_error", "interpreter exec runs a script, not run", nil, nil)
+	}
+	return nil
+}
+
+// scriptCommand builds the command a rite executes: the script or inline run body under the interpreter,
+// followed by args. Inline shell bodies see args as $1.., with the daemon name as $0
+func scriptCommand(meta *DaemonMeta) *exec.Cmd {
+	interpreter := meta.Interpreter
+	if interpreter == "" {
+		interpreter = defaultInterpreter
+	}
+
+	switch {
+	case interpreter == interpreterExec:
+		return exec.Command(meta.ScriptPath, meta.Args...)
+	case meta.Run != "" && interpreter == interpreterPython:
+		return exec.Command(interpreter, append([]string{"-c", meta.Run}, meta.Args...)...)
+	case meta.Run != "":
+		return exec.Command(interpreter, append([]string{"-c", meta.Run, meta.Name}, meta.Args...)...)
+	default:
+		return exec.Command(interpreter, append([]string{meta.ScriptPath}, meta.Args...)...)
+	}
+}
+
+// scriptLabel names what a daemon runs, for messages & listings
+func scriptLabel(meta *DaemonMeta) string {
+	if meta.Run == "" {
+		return meta.ScriptPath
+	}
+	body := strings.TrimSpace(meta.Run)
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[:i] + " …"
+	}
+	return "run: " + body
+}
+
+func completeInterpreters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return interpreters, cobra.ShellCompDirectiveNoFileComp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,249 @@
+/*
+Copyright © 2025 Daniel Rivas <danielrivasmd@gmail.com>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DanielRivasMD/horus"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Watcher translates daemon metadata into the command line of a file-watching backend
+type Watcher interface {
+	Command(meta *DaemonMeta) (*exec.Cmd, error)
+}
+
+// defaultBackend is used when neither flag, TOML nor metadata names a backend
+const defaultBackend = "native"
+
+// watchers maps the `backend` key of a workflow to its implementation
+var watchers = map[string]Watcher{
+	"native":    nativeWatcher{},
+	"poll":      pollWatcher{},
+	"watchexec": watchexecWatcher{},
+	"entr":      entrWatcher{},
+}
+
+// lookupWatcher resolves a backend name, falling back to defaultBackend when empty
+func lookupWatcher(name string) (Watcher, error) {
+	const op = "watcher.lookup"
+
+	if name == "" {
+		name = defaultBackend
+	}
+	w, ok := watchers[name]
+	if !ok {
+		return nil, horus.NewCategorizedHerror(
+			op, "config_error", "unknown watcher backend", nil,
+			map[string]any{"backend": name, "available": strings.Join(backendNames(), ", ")},
+		)
+	}
+	return w, nil
+}
+
+// backendNames lists registered backends in stable order
+func backendNames() []string {
+	var out []string
+	for name := range watchers {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// filterValidator is implemented by backends unable to honour every filter key,
+// so invoke can refuse the workflow before spawning anything
+type filterValidator interface {
+	validateFilters(wf Workflow) error
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// nativeWatcher re-executes lilith as an fsnotify watcher
+type nativeWatcher struct{}
+
+func (nativeWatcher) Command(meta *DaemonMeta) (*exec.Cmd, error) {
+	args := append([]string{"haunt", "--name", meta.Name}, watchArgs(meta)...)
+	return selfCommand(append(args, hauntFilterArgs(meta, true)...)...)
+}
+
+// pollWatcher re-executes lilith as a stat-polling watcher, for mounts where inotify is silent
+type pollWatcher struct{}
+
+func (pollWatcher) Command(meta *DaemonMeta) (*exec.Cmd, error) {
+	args := append([]string{"haunt", "--poll", "--name", meta.Name}, watchArgs(meta)...)
+	return selfCommand(append(args, hauntFilterArgs(meta, true)...)...)
+}
+
+// watchArgs repeats --watch for each watched path, as haunt & watchexec both expect
+func watchArgs(meta *DaemonMeta) []string {
+	var args []string
+	for _, p := range meta.WatchPaths {
+		args = append(args, "--watch", p)
+	}
+	return args
+}
+
+// hauntFilterArgs translates the filter keys of a daemon into haunt flags;
+// entr only borrows the path filters, having no notion of debounce or events
+func hauntFilterArgs(meta *DaemonMeta, timing bool) []string {
+	var args []string
+	if timing && meta.Debounce != "" {
+		args = append(args, "--debounce", meta.Debounce)
+	}
+	for _, pattern := range meta.Ignore {
+		args = append(args, "--ignore", pattern)
+	}
+	if len(meta.Extensions) > 0 {
+		args = append(args, "--extensions", strings.Join(meta.Extensions, ","))
+	}
+	if timing && len(meta.Events) > 0 {
+		args = append(args, "--events", strings.Join(meta.Events, ","))
+	}
+	return args
+}
+
+// watchexecWatcher delegates to watchexec, which hands changed paths to the rite through its environment
+type watchexecWatcher struct{}
+
+func (watchexecWatcher) Command(meta *DaemonMeta) (*exec.Cmd, error) {
+	rite, err := riteCommand(meta.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	args := watchArgs(meta)
+	if meta.Debounce != "" {
+		d, err := time.ParseDuration(meta.Debounce)
+		if err != nil {
+			return nil, horus.NewCategorizedHerror("watcher.watchexec", "config_error", "parsing debounce", err, map[string]any{"daemon": meta.Name})
+		}
+		args = append(args, "--debounce", strconv.FormatInt(d.Milliseconds(), 10))
+	}
+	for _, pattern := range meta.Ignore {
+		args = append(args, "--ignore", pattern)
+	}
+	if len(meta.Extensions) > 0 {
+		args = append(args, "--exts", strings.Join(meta.Extensions, ","))
+	}
+	if len(meta.Events) > 0 {
+		args = append(args, "--fs-events", strings.Join(watchexecEvents(meta.Events), ","))
+	}
+	// watchexec 2 only exports WATCHEXEC_*_PATH, read back by the rite, when asked to
+	args = append(args, "--emit-events-to", "environment", "--")
+	return externalCommand("watchexec", append(args, rite.Args...)...)
+}
+
+// validateFilters refuses negated ignore patterns, which watchexec's --ignore has no syntax for
+func (watchexecWatcher) validateFilters(wf Workflow) error {
+	for _, pattern := range wf.Ignore {
+		if strings.HasPrefix(pattern, "!") {
+			return horus.NewCategorizedHerror(
+				"watcher.watchexec", "config_error", "the watchexec backend does not support negated ignore patterns", nil,
+				map[string]any{"pattern": pattern},
+			)
+		}
+	}
+	return nil
+}
+
+// watchexecEvents names event kinds as --fs-events does; attribute changes count as modify, as with haunt
+func watchexecEvents(events []string) []string {
+	var out []string
+	for _, kind := range events {
+		out = append(out, kind)
+		if kind == eventModify {
+			out = append(out, "metadata")
+		}
+	}
+	return out
+}
+
+// entrWatcher delegates to entr, restarting it whenever a new file appears so the list stays current
+type entrWatcher struct{}
+
+// entrLoop feeds entr the files haunt --list selects & runs the rite for daemon $1 through lilith at $2,
+// with entr substituting /_ by the changed file; entr exits with 2 under -d when a directory gains a file,
+// whereupon the files new to the list are handed to the rite as created. The rite emulates debounce & events,
+// which entr lacks. The remaining arguments are the haunt flags naming the watch path & its filters
+const entrLoop = `name=$1 self=$2; shift 2; list=$("$self" haunt --list "$@"); ` +
+	`while :; do printf '%s\n' "$list" | entr -d -n "$self" rite --name "$name" --emulate -- /_; status=$?; [ "$status" -eq 2 ] || exit "$status"; ` +
+	`old=$list; list=$("$self" haunt --list "$@"); added=$(printf '%s\n' "$list" | grep -vxF -e "$old"); ` +
+	`[ -z "$added" ] || printf '%s\n' "$added" | tr '\n' '\0' | xargs -0 "$self" rite --name "$name" --emulate --created --; done`
+
+func (entrWatcher) Command(meta *DaemonMeta) (*exec.Cmd, error) {
+	if _, err := lookupExecutable("entr"); err != nil {
+		return nil, err
+	}
+	self, err := selfCommand()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-c", entrLoop, "lilith-entr", meta.Name, self.Path}, watchArgs(meta)...)
+	args = append(args, hauntFilterArgs(meta, false)...)
+	return externalCommand("sh", args...)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// selfCommand builds a command re-executing the running lilith binary
+func selfCommand(args ...string) (*exec.Cmd, error) {
+	const op = "watcher.self"
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, horus.NewCategorizedHerror(op, "env_error", "locating lilith executable", err, nil)
+	}
+	return exec.Command(self, args...), nil
+}
+
+// externalCommand builds a command for a binary that must be present on PATH
+func externalCommand(name string, args ...string) (*exec.Cmd, error) {
+	path, err := lookupExecutable(name)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path, args...), nil
+}
+
+// lookupExecutable reports a readable error when a backend binary is missing
+func lookupExecutable(name string) (string, error) {
+	const op = "watcher.lookPath"
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", horus.NewCategorizedHerror(
+			op, "env_error", fmt.Sprintf("%s not found on PATH", name), err,
+			map[string]any{"binary": name},
+		)
+	}
+	return path, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////